@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// CategoryOther is the category of the competitors without a gender and a category on the roster.
+const CategoryOther = "Other"
+
+// category returns the ranking category of the competitor made of his/her gender and age category, e.g. "F Junior".
+func (c Competitor) category() string {
+	if category := strings.TrimSpace(c.Gender + " " + c.Category); category != "" {
+		return category
+	}
+	return CategoryOther
+}
+
+// inCategory reports whether the competitor belongs to the category given by the gender,
+// the age category or both, e.g. "F", "Junior" or "F Junior".
+func (c Competitor) inCategory(category string) bool {
+	return category == c.Gender || category == c.Category || category == c.category()
+}
+
+// hasCategories reports whether the roster splits the competitors into categories ranked separately.
+func (cfg Config) hasCategories() bool {
+	for _, c := range cfg.competitors {
+		if c.Gender != "" || c.Category != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByCategory splits the results by category, keeping their order within every category.
+// The categories are returned sorted by name.
+func groupByCategory(results []Result) ([]string, map[string][]Result) {
+	byCategory := make(map[string][]Result)
+	for _, r := range results {
+		category := r.Competitor.category()
+		byCategory[category] = append(byCategory[category], r)
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+	return categories, byCategory
+}
+
+// writeCategoryResults writes a separate ranking for every category.
+// The results keep the order of the final report, the finished competitors are numbered within the category.
+func writeCategoryResults(w io.Writer, results []Result) {
+	categories, byCategory := groupByCategory(results)
+	for _, category := range categories {
+		fmt.Fprintln(w, "[Category]", category)
+
+		ranked := byCategory[category]
+		numbers := ranks(ranked)
+		for _, r := range ranked {
+			if rank, ok := numbers[r.CompetitorID]; ok {
+				fmt.Fprintf(w, "%d. %s\n", rank, r)
+			} else {
+				fmt.Fprintln(w, r)
+			}
+		}
+	}
+}
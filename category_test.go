@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompetitorCategory(t *testing.T) {
+	assert.Equal(t, "F Junior", Competitor{Gender: "F", Category: "Junior"}.category())
+	assert.Equal(t, "M", Competitor{Gender: "M"}.category())
+	assert.Equal(t, "Youth", Competitor{Category: "Youth"}.category())
+	assert.Equal(t, CategoryOther, Competitor{Name: "Anna Berg"}.category())
+}
+
+func TestHasCategories(t *testing.T) {
+	assert.False(t, Config{}.hasCategories())
+	assert.False(t, Config{competitors: map[int]Competitor{1: {ID: 1, Name: "Anna Berg"}}}.hasCategories())
+	assert.True(t, Config{competitors: map[int]Competitor{1: {ID: 1, Gender: "F"}}}.hasCategories())
+}
+
+func TestGenerateReportCategories(t *testing.T) {
+	cfg := Config{
+		Laps:        1,
+		FiringLines: 1,
+		competitors: map[int]Competitor{
+			1: {ID: 1, Gender: "M"},
+			2: {ID: 2, Gender: "F"},
+			3: {ID: 3, Gender: "M"},
+			4: {ID: 4, Gender: "F"},
+		},
+	}
+	summary := Summary{
+		1: {CompetitorID: 1, Status: StatusFinished, TotalRaceDuration: 12 * time.Minute},
+		2: {CompetitorID: 2, Status: StatusFinished, TotalRaceDuration: 11 * time.Minute},
+		3: {CompetitorID: 3, Status: StatusFinished, TotalRaceDuration: 10 * time.Minute},
+		4: {CompetitorID: 4, Status: StatusCantContinue},
+		5: {CompetitorID: 5, Status: StatusFinished, TotalRaceDuration: 9 * time.Minute},
+	}
+
+	var buf bytes.Buffer
+	generateReport(&buf, cfg, summary)
+
+	want := "[Category] F\n" +
+		"[DNF] 4 [] {,} 0/5\n" +
+		"1. [00:11:00.000] 2 [] {,} 0/5\n" +
+		"[Category] M\n" +
+		"1. [00:10:00.000] 3 [] {,} 0/5\n" +
+		"2. [00:12:00.000] 1 [] {,} 0/5\n" +
+		"[Category] Other\n" +
+		"1. [00:09:00.000] 5 [] {,} 0/5\n"
+	assert.Equal(t, want, buf.String())
+
+	entries, err := readResults(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, 4, entries[0].CompetitorID)
+	assert.Equal(t, ResultEntry{CompetitorID: 2, Status: "00:11:00.000", Time: 11 * time.Minute, Finished: true}, entries[1])
+}
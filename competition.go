@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// loadCompetitions parses the competitions of a config. Every competition is decoded over
+// the top level of the config, so it inherits all the fields it doesn't set.
+func loadCompetitions(path string, data []byte) ([]Config, error) {
+	var raw struct {
+		Competitions []json.RawMessage `json:"competitions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	competitions := make([]Config, 0, len(raw.Competitions))
+	for i, r := range raw.Competitions {
+		var c Config
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("parsing config: %w", err)
+		}
+		c.Competitions = nil
+		if err := json.Unmarshal(r, &c); err != nil {
+			return nil, fmt.Errorf("parsing competition %d: %w", i+1, err)
+		}
+		given, err := givenFields(data, r)
+		if err != nil {
+			return nil, err
+		}
+		c.given = given
+
+		if c.ID == "" {
+			return nil, fmt.Errorf("competition %d has no 'id'", i+1)
+		}
+		if c.Competitions != nil {
+			return nil, fmt.Errorf("competition %s can't have competitions", c.ID)
+		}
+		if slices.ContainsFunc(competitions, func(other Config) bool { return other.ID == c.ID }) {
+			return nil, fmt.Errorf("competition %s is defined twice", c.ID)
+		}
+		if err := c.prepare(path); err != nil {
+			return nil, fmt.Errorf("competition %s: %w", c.ID, err)
+		}
+
+		competitions = append(competitions, c)
+	}
+
+	return competitions, nil
+}
+
+// routeEvent returns the index of the competition the event belongs to.
+// The competition given in the event line takes precedence, otherwise it is
+// the only competition with the competitor on its roster.
+func (cfg Config) routeEvent(evt Event) (int, error) {
+	if evt.Competition != "" {
+		i := slices.IndexFunc(cfg.Competitions, func(c Config) bool { return c.ID == evt.Competition })
+		if i < 0 {
+			return 0, fmt.Errorf("unknown competition: %s", evt.Competition)
+		}
+		return i, nil
+	}
+
+	found := -1
+	for i, c := range cfg.Competitions {
+		if _, ok := c.competitors[evt.CompetitorID]; !ok {
+			continue
+		}
+		if found >= 0 {
+			return 0, fmt.Errorf("competitor(%d) is entered in competitions %s and %s",
+				evt.CompetitorID, cfg.Competitions[found].ID, c.ID)
+		}
+		found = i
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("competitor(%d) is not entered in any competition", evt.CompetitorID)
+	}
+	return found, nil
+}
+
+// runCompetitions processes the events of several competitions sharing one event stream.
+// Every event is routed to its competition, the final reports follow one another in the order of the config.
+func runCompetitions(eventsReader io.Reader, logWriter io.Writer, cfg Config, board *Leaderboard) {
+	summaries := processCompetitions(eventsReader, logWriter, cfg, board)
+	for i, c := range cfg.Competitions {
+		fmt.Fprintln(logWriter, "[Competition]", c.ID)
+		generateReport(logWriter, c, summaries[i])
+	}
+}
+
+// processCompetitions routes every event to its competition and returns the summaries in the order of the config.
+func processCompetitions(eventsReader io.Reader, logWriter io.Writer, cfg Config, board *Leaderboard) []Summary {
+	processors := make([]*processor, len(cfg.Competitions))
+	for i, c := range cfg.Competitions {
+		processors[i] = newProcessor(c)
+		processors[i].board = board
+	}
+
+	for evt := range parseEvents(eventsReader, logWriter) {
+		i, err := cfg.routeEvent(evt)
+		if err != nil {
+			logError(logWriter, "routing", err)
+			continue
+		}
+		processors[i].process(logWriter, evt)
+	}
+
+	summaries := make([]Summary, len(processors))
+	for i, p := range processors {
+		summaries[i] = p.finish()
+	}
+	return summaries
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigCompetitions(t *testing.T) {
+	cfg, err := loadConfig("examples/competitions/config.json")
+	require.NoError(t, err)
+	require.Len(t, cfg.Competitions, 2)
+
+	women, men := cfg.Competitions[0], cfg.Competitions[1]
+	assert.Equal(t, "women", women.ID)
+	assert.Equal(t, LapLengths{3000}, women.LapLen)
+	assert.Equal(t, LapLengths{3500}, men.LapLen)
+
+	// The fields not set by a competition are inherited from the top level.
+	assert.Equal(t, 2, women.Laps)
+	assert.Equal(t, cfg.Start, men.Start)
+
+	// The roster is split by categories.
+	assert.Len(t, women.competitors, 2)
+	assert.Len(t, men.competitors, 3)
+}
+
+func TestLoadConfigCompetitionsProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"profile": "superSprint", "start": "10:00:00", "competitions": [{"id": "a"}, {"id": "b", "spareRounds": 0}]}`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+
+	// A zero given by a competition wins over the profile it inherits.
+	cfg, err := loadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Competitions[0].SpareRounds)
+	assert.Equal(t, 0, cfg.Competitions[1].SpareRounds)
+}
+
+func TestLoadConfigCompetitionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "missing id", config: `{"competitions": [{"laps": 2}]}`, wantErr: "competition 1 has no 'id'"},
+		{name: "duplicate id", config: `{"laps": 1, "lapLen": 1000, "competitions": [{"id": "a"}, {"id": "a"}]}`, wantErr: "competition a is defined twice"},
+		{name: "nested", config: `{"competitions": [{"id": "a", "competitions": [{"id": "b"}]}]}`, wantErr: "competition a can't have competitions"},
+		{name: "invalid", config: `{"competitions": [{"id": "a", "laps": "two"}]}`, wantErr: "Config.competitions.0.laps"},
+		{name: "unknown profile", config: `{"competitions": [{"id": "a", "profile": "marathon"}]}`, wantErr: "competition a: unknown profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+
+			_, err := loadConfig(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRouteEvent(t *testing.T) {
+	cfg := Config{
+		Competitions: []Config{
+			{ID: "women", competitors: map[int]Competitor{1: {ID: 1}, 3: {ID: 3}}},
+			{ID: "men", competitors: map[int]Competitor{2: {ID: 2}, 3: {ID: 3}}},
+			{ID: "youth"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		evt     Event
+		want    int
+		wantErr string
+	}{
+		{name: "by roster", evt: Event{CompetitorID: 2}, want: 1},
+		{name: "explicit", evt: Event{CompetitorID: 7, Competition: "youth"}, want: 2},
+		{name: "explicit over roster", evt: Event{CompetitorID: 3, Competition: "women"}, want: 0},
+		{name: "unknown competition", evt: Event{CompetitorID: 1, Competition: "masters"}, wantErr: "unknown competition: masters"},
+		{name: "not entered", evt: Event{CompetitorID: 7}, wantErr: "not entered in any competition"},
+		{name: "ambiguous", evt: Event{CompetitorID: 3}, wantErr: "entered in competitions women and men"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.routeEvent(tt.evt)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompetitorInCategory(t *testing.T) {
+	c := Competitor{Gender: "F", Category: "Junior"}
+	assert.True(t, c.inCategory("F"))
+	assert.True(t, c.inCategory("Junior"))
+	assert.True(t, c.inCategory("F Junior"))
+	assert.False(t, c.inCategory("M"))
+}
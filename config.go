@@ -2,20 +2,108 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Competition formats.
+const (
+	FormatInterval = ""          // Interval start, start times are set by a draw
+	FormatPursuit  = "pursuit"   // Handicap start, start times are seeded from previous results
+	FormatMass     = "massStart" // Simultaneous start of all competitors at the configured start time
+	FormatRelay    = "relay"     // Teams of competitors running consecutive legs
+)
+
 // Config represents the configuration for the biathlon competition.
 type Config struct {
-	Laps        int      `json:"laps"`
-	LapLen      int      `json:"lapLen"`
-	PenaltyLen  int      `json:"penaltyLen"`
-	FiringLines int      `json:"firingLines"`
-	Start       Time     `json:"start"`
-	StartDelta  Duration `json:"startDelta"`
+	Laps        int        `json:"laps"`
+	LapLen      LapLengths `json:"lapLen"`
+	PenaltyLen  int        `json:"penaltyLen"`
+	FiringLines int        `json:"firingLines"`
+	Start       Time       `json:"start"`
+	StartDelta  Duration   `json:"startDelta"`
+	Format      string     `json:"format"`
+	Seeds       string     `json:"seeds"`
+	PullLapped  bool       `json:"pullLapped"`
+	Lanes       int        `json:"lanes"`
+	SpareRounds int        `json:"spareRounds"`
+	Teams       []Team     `json:"teams"`
+	PenaltyTime Duration   `json:"penaltyTime"`
+	Positions   []string   `json:"positions"`
+	Targets     int        `json:"targets"`
+	Profile     string     `json:"profile"`
+
+	Profiles map[string]Profile `json:"profiles"`
+
+	// How late a competitor may start after the scheduled start time, defaults to StartDelta.
+	// Zero means the competitor has to start exactly on time.
+	StartTolerance *Duration `json:"startTolerance"`
+
+	// Path to the roster file with the names, nations and categories of the competitors.
+	Roster string `json:"roster"`
+
+	// Identifies a competition in a config with several competitions.
+	ID string `json:"id"`
+
+	// Roster categories entered in the competition, all of them by default.
+	Categories []string `json:"categories"`
+
+	// Competitions sharing the event stream. Each competition inherits the fields it doesn't set from the top level.
+	Competitions []Config `json:"competitions"`
+
+	// Classification of the nations or clubs by their best finishers.
+	TeamClassification *TeamClassification `json:"teamClassification"`
+
+	// Start times seeded from the results file for a pursuit.
+	startTimes map[int]time.Time
+
+	// Competitors on the roster by ID, nil if there is no roster.
+	competitors map[int]Competitor
+
+	// Fields given in the config file, even if they are zero.
+	given map[string]bool
+}
+
+// Team represents a relay team. The members run the legs in the given order.
+type Team struct {
+	ID      int   `json:"id"`
+	Members []int `json:"members"`
+}
+
+// LapLengths is a custom type for the lengths of the main laps.
+// It is unmarshaled either from a single length of every lap or from a list with the length of each lap.
+type LapLengths []int
+
+func (l *LapLengths) UnmarshalJSON(b []byte) error {
+	var length int
+	if err := json.Unmarshal(b, &length); err == nil {
+		*l = LapLengths{length}
+		return nil
+	}
+	var lengths []int
+	if err := json.Unmarshal(b, &lengths); err != nil {
+		return fmt.Errorf("invalid 'lapLen' format: %s", b)
+	}
+	*l = lengths
+	return nil
+}
+
+// Of returns the length of the lap with the given zero-based index.
+func (l LapLengths) Of(lap int) int {
+	switch {
+	case len(l) == 1:
+		return l[0]
+	case lap >= 0 && lap < len(l):
+		return l[lap]
+	default:
+		return 0
+	}
 }
 
 // Time is a custom type that embeds time.Time and provides custom JSON unmarshaling.
@@ -25,6 +113,9 @@ type Time struct {
 }
 
 func (t *Time) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
 	s := strings.Trim(string(b), `"`)
 	layouts := []string{
 		"15:04:05.000", // Format with milliseconds
@@ -41,44 +132,284 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 	return fmt.Errorf("invalid 'start' format: %s", s)
 }
 
+// MarshalJSON writes the time in the format it is read in, a zero time is written as null.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format("15:04:05.000"))
+}
+
 // Duration is a custom type that embeds time.Duration and provides custom JSON unmarshaling.
-// It is used to parse the 'StartDelta' field in the configuration.
+// It is used to parse the 'StartDelta' field and other durations in the configuration.
+// A duration is written either as a clock time with optional milliseconds ("00:00:30.500"),
+// as a Go duration ("1m30s") or as a number of seconds (30.5).
 type Duration struct {
 	time.Duration
 }
 
 func (d *Duration) UnmarshalJSON(b []byte) error {
-	s := strings.Trim(string(b), `"`)
-	layouts := []string{
-		"15:04:05.000", // Format with milliseconds
-		time.TimeOnly,  // Format without milliseconds
+	if string(b) == "null" {
+		return nil
 	}
-	for _, layout := range layouts {
-		t, err := time.Parse(layout, s)
-		if err == nil {
-			d.Duration = time.Duration(t.Hour())*time.Hour +
-				time.Duration(t.Minute())*time.Minute +
-				time.Duration(t.Second())*time.Second
-			return nil
+
+	var seconds float64
+	if err := json.Unmarshal(b, &seconds); err == nil {
+		d.Duration = time.Duration(seconds * float64(time.Second))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid duration format: %s", b)
+	}
+	if parsed, err := parseClockDuration(s); err == nil {
+		d.Duration = parsed
+		return nil
+	}
+	if parsed, err := time.ParseDuration(s); err == nil {
+		d.Duration = parsed
+		return nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		d.Duration = time.Duration(seconds * float64(time.Second))
+		return nil
+	}
+	return fmt.Errorf("invalid duration format: %s", s)
+}
+
+// MarshalJSON writes the duration as a clock time with milliseconds, e.g. "00:00:30.500".
+// A duration the clock can't hold, of a day or more or with a fraction of a millisecond,
+// is written as a Go duration instead, e.g. "25h0m0s", so that it is read back unchanged.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	abs := d.Abs()
+	if abs >= 24*time.Hour || abs%time.Millisecond != 0 {
+		return json.Marshal(d.String())
+	}
+	if d.Duration < 0 {
+		return json.Marshal("-" + formatDuration(abs))
+	}
+	return json.Marshal(formatDuration(abs))
+}
+
+// ranksByFinishTime reports whether the first competitor across the line wins.
+func (cfg Config) ranksByFinishTime() bool {
+	return cfg.Format == FormatPursuit || cfg.Format == FormatMass || cfg.Format == FormatRelay
+}
+
+// startTolerance returns how late a competitor may start after the scheduled start time.
+func (cfg Config) startTolerance() time.Duration {
+	if cfg.StartTolerance != nil {
+		return cfg.StartTolerance.Duration
+	}
+	return cfg.StartDelta.Duration
+}
+
+// targets returns the number of targets per bout.
+func (cfg Config) targets() int {
+	if cfg.Targets > 0 {
+		return cfg.Targets
+	}
+	return NumberOfTargets
+}
+
+// hasStartWindow reports whether competitors have to start within their own start interval.
+func (cfg Config) hasStartWindow() bool {
+	return cfg.Format == FormatInterval || cfg.Format == FormatPursuit
+}
+
+// teamOf returns the relay team of the competitor and the index of the leg he/she runs.
+func (cfg Config) teamOf(competitorID int) (Team, int, bool) {
+	for _, team := range cfg.Teams {
+		for leg, member := range team.Members {
+			if member == competitorID {
+				return team, leg, true
+			}
 		}
 	}
-	return fmt.Errorf("invalid 'startDelta' format: %s", s)
+	return Team{}, 0, false
+}
+
+// competitor returns the roster entry of the competitor, which is empty if there is none.
+func (cfg Config) competitor(competitorID int) Competitor {
+	return cfg.competitors[competitorID]
 }
 
 // loadConfig reads and parses the configuration file from the given path.
+// The file may be written in JSON, YAML or TOML.
 // It returns a Config object or an error if the file cannot be read or parsed.
 func loadConfig(path string) (Config, error) {
-	file, err := os.Open(path)
+	return loadConfigWith(path, nil)
+}
+
+// loadConfigWith loads the config file with the given fields overridden.
+func loadConfigWith(path string, overrides Overrides) (Config, error) {
+	data, err := readConfigFile(path)
 	if err != nil {
 		return Config{}, err
 	}
-	defer file.Close()
+	if data, err = overrides.apply(data); err != nil {
+		return Config{}, err
+	}
 
 	var cfg Config
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&cfg); err != nil {
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("parsing config: %w", err)
 	}
+	if cfg.given, err = givenFields(data); err != nil {
+		return Config{}, err
+	}
+
+	// The top level of a config with several competitions only holds the fields they inherit.
+	if len(cfg.Competitions) > 0 {
+		cfg.Competitions, err = loadCompetitions(path, data)
+		if err != nil {
+			return Config{}, err
+		}
+		return cfg, nil
+	}
+
+	if err := cfg.prepare(path); err != nil {
+		return Config{}, err
+	}
 
 	return cfg, nil
 }
+
+// givenFields returns the fields set by the JSON configs, so that an explicit zero
+// can be told apart from a missing field. A field set to null counts as missing.
+func givenFields(configs ...[]byte) (map[string]bool, error) {
+	given := make(map[string]bool)
+	for _, data := range configs {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("parsing config: %w", err)
+		}
+		for name, raw := range fields {
+			if string(raw) != "null" {
+				given[name] = true
+			}
+		}
+	}
+	return given, nil
+}
+
+// prepare applies the profile, checks the config and loads the files it refers to.
+func (cfg *Config) prepare(path string) error {
+	if err := cfg.applyProfile(); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var err error
+	if cfg.Format == FormatPursuit {
+		cfg.startTimes, err = loadPursuitSeeds(relativeTo(path, cfg.Seeds), cfg.Start.Time)
+		if err != nil {
+			return fmt.Errorf("loading pursuit seeds: %w", err)
+		}
+	}
+
+	if cfg.Roster != "" {
+		cfg.competitors, err = loadRoster(relativeTo(path, cfg.Roster))
+		if err != nil {
+			return fmt.Errorf("loading roster: %w", err)
+		}
+	}
+
+	// Only the competitors of the given categories are entered in the competition.
+	if len(cfg.Categories) > 0 {
+		maps.DeleteFunc(cfg.competitors, func(_ int, c Competitor) bool {
+			return !slices.ContainsFunc(cfg.Categories, c.inCategory)
+		})
+	}
+
+	return nil
+}
+
+// Validate checks the ranges of the fields and their consistency with each other.
+// Every problem found is reported with the name of the field in the config file.
+func (cfg Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Laps > 0, "'laps' must be positive, got %d", cfg.Laps)
+	check(len(cfg.LapLen) > 0, "'lapLen' is required")
+	check(len(cfg.LapLen) <= 1 || len(cfg.LapLen) == cfg.Laps,
+		"'lapLen' has %d lengths for %d laps", len(cfg.LapLen), cfg.Laps)
+	for i, length := range cfg.LapLen {
+		check(length > 0, "'lapLen[%d]' must be positive, got %d", i, length)
+	}
+	check(cfg.PenaltyLen >= 0, "'penaltyLen' must not be negative, got %d", cfg.PenaltyLen)
+	check(cfg.PenaltyTime.Duration >= 0, "'penaltyTime' must not be negative, got %s", cfg.PenaltyTime)
+	check(cfg.FiringLines >= 0, "'firingLines' must not be negative, got %d", cfg.FiringLines)
+	check(cfg.FiringLines <= cfg.Laps,
+		"'firingLines' must not be greater than 'laps', got %d firing lines for %d laps", cfg.FiringLines, cfg.Laps)
+	check(cfg.FiringLines == 0 || cfg.PenaltyLen > 0 || cfg.PenaltyTime.Duration > 0,
+		"'penaltyLen' or 'penaltyTime' is required with firing lines")
+	check(cfg.StartDelta.Duration >= 0, "'startDelta' must not be negative, got %s", cfg.StartDelta)
+	check(cfg.StartTolerance == nil || cfg.StartTolerance.Duration >= 0,
+		"'startTolerance' must not be negative, got %s", cfg.StartTolerance)
+	check(cfg.Lanes >= 0, "'lanes' must not be negative, got %d", cfg.Lanes)
+	check(cfg.SpareRounds >= 0, "'spareRounds' must not be negative, got %d", cfg.SpareRounds)
+	check(cfg.Targets >= 0, "'targets' must not be negative, got %d", cfg.Targets)
+
+	check(len(cfg.Positions) == 0 || len(cfg.Positions) == cfg.FiringLines,
+		"'positions' has %d positions for %d firing lines", len(cfg.Positions), cfg.FiringLines)
+	for i, position := range cfg.Positions {
+		check(position == PositionProne || position == PositionStanding,
+			"'positions[%d]' must be %q or %q, got %q", i, PositionProne, PositionStanding, position)
+	}
+
+	switch cfg.Format {
+	case FormatInterval:
+	case FormatPursuit:
+		check(cfg.Seeds != "", "'seeds' is required for a pursuit")
+		check(!cfg.Start.IsZero(), "'start' is required for a pursuit")
+	case FormatMass:
+		check(!cfg.Start.IsZero(), "'start' is required for a mass start")
+	case FormatRelay:
+		check(!cfg.Start.IsZero(), "'start' is required for a relay")
+		check(len(cfg.Teams) > 0, "'teams' are required for a relay")
+	default:
+		check(false, "'format' must be empty, %q, %q or %q, got %q", FormatPursuit, FormatMass, FormatRelay, cfg.Format)
+	}
+
+	teams := make(map[int]bool)
+	members := make(map[int]int)
+	for i, team := range cfg.Teams {
+		check(!teams[team.ID], "'teams[%d].id' %d is used by another team", i, team.ID)
+		teams[team.ID] = true
+		check(len(team.Members) > 0, "'teams[%d].members' must not be empty", i)
+		for _, member := range team.Members {
+			other, taken := members[member]
+			check(!taken, "'teams[%d].members' competitor(%d) is already a member of team(%d)", i, member, other)
+			members[member] = team.ID
+		}
+	}
+
+	check(len(cfg.Categories) == 0 || cfg.Roster != "", "'categories' require a 'roster'")
+
+	if cfg.TeamClassification != nil {
+		if err := cfg.TeamClassification.validate(); err != nil {
+			errs = append(errs, err)
+		}
+		check(cfg.Roster != "", "'teamClassification' requires a 'roster'")
+	}
+
+	return errors.Join(errs...)
+}
+
+// relativeTo resolves a path given in the config file relative to the directory of the config file.
+func relativeTo(cfgPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(cfgPath), path)
+}
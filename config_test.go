@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
@@ -28,7 +29,7 @@ func TestLoadConfig(t *testing.T) {
 			}`,
 			wantConfig: Config{
 				Laps:        2,
-				LapLen:      3651,
+				LapLen:      LapLengths{3651},
 				PenaltyLen:  50,
 				FiringLines: 1,
 				Start:       Time{parseTime(t, time.TimeOnly, "09:30:00")},
@@ -48,14 +49,45 @@ func TestLoadConfig(t *testing.T) {
 			}`,
 			wantConfig: Config{
 				Laps:        3,
-				LapLen:      1000,
+				LapLen:      LapLengths{1000},
 				PenaltyLen:  25,
 				FiringLines: 2,
 				Start:       Time{parseTime(t, "15:04:05.000", "12:34:56.789")},
-				StartDelta:  Duration{2*time.Minute + 3*time.Second},
+				StartDelta:  Duration{2*time.Minute + 3*time.Second + 456*time.Millisecond},
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid config with lengths per lap",
+			configJSON: `{
+				"laps": 3,
+				"lapLen": [3300, 3300, 3400],
+				"start": "10:00:00",
+				"startDelta": "00:00:30"
+			}`,
+			wantConfig: Config{
+				Laps:       3,
+				LapLen:     LapLengths{3300, 3300, 3400},
+				Start:      Time{parseTime(t, time.TimeOnly, "10:00:00")},
+				StartDelta: Duration{30 * time.Second},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid number of lap lengths",
+			configJSON: `{
+				"laps": 2,
+				"lapLen": [3300, 3300, 3400]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid lapLen format",
+			configJSON: `{
+				"lapLen": "long"
+			}`,
+			wantErr: true,
+		},
 		{
 			name: "invalid start time format",
 			configJSON: `{
@@ -119,6 +151,313 @@ func TestLoadConfig_FileNotFound(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestLoadConfigProfile(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "config*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(`{"profile": "sprint", "laps": 2, "start": "10:00:00"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(tmpfile.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cfg.Laps)
+	assert.Equal(t, LapLengths{3333}, cfg.LapLen)
+	assert.Equal(t, 2, cfg.FiringLines)
+	assert.Equal(t, 30*time.Second, cfg.StartDelta.Duration)
+}
+
+func TestLoadConfigProfileExplicitZero(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "config*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(`{"profile": "superSprint", "start": "10:00:00", "spareRounds": 0, "startDelta": 0, "positions": null}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The zeros given in the config win over the profile, a null is filled in from it.
+	cfg, err := loadConfig(tmpfile.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cfg.SpareRounds)
+	assert.Equal(t, time.Duration(0), cfg.StartDelta.Duration)
+	assert.Equal(t, []string{PositionProne, PositionProne, PositionStanding, PositionStanding}, cfg.Positions)
+	assert.Equal(t, 75, cfg.PenaltyLen)
+}
+
+func TestLoadConfigUnknownProfile(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "config*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(`{"profile": "marathon"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadConfig(tmpfile.Name())
+	assert.NotNil(t, err)
+}
+
+func TestLoadConfigPursuit(t *testing.T) {
+	cfg, err := loadConfig("examples/pursuit/config.json")
+	assert.Nil(t, err)
+	assert.Equal(t, FormatPursuit, cfg.Format)
+	assert.True(t, cfg.PullLapped)
+	assert.Len(t, cfg.startTimes, 5)
+	assert.Equal(t, cfg.Start.Time, cfg.startTimes[2])
+}
+
+func TestLoadConfigPursuitSeedsNotFound(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "config*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(`{"laps": 2, "lapLen": 2500, "start": "11:00:00", "format": "pursuit", "seeds": "non_existent_file"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadConfig(tmpfile.Name())
+	assert.ErrorContains(t, err, "loading pursuit seeds")
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Laps: 2, LapLen: LapLengths{3000}, PenaltyLen: 150, FiringLines: 1}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   []string
+	}{
+		{
+			name:   "missing fields",
+			modify: func(cfg *Config) { *cfg = Config{} },
+			want:   []string{"'laps' must be positive", "'lapLen' is required"},
+		},
+		{
+			name: "negative lengths",
+			modify: func(cfg *Config) {
+				cfg.LapLen = LapLengths{3000, -1}
+				cfg.PenaltyLen = -150
+			},
+			want: []string{"'lapLen[1]' must be positive", "'penaltyLen' must not be negative"},
+		},
+		{
+			name:   "too many firing lines",
+			modify: func(cfg *Config) { cfg.FiringLines = 3 },
+			want:   []string{"'firingLines' must not be greater than 'laps'"},
+		},
+		{
+			name:   "no penalty",
+			modify: func(cfg *Config) { cfg.PenaltyLen = 0 },
+			want:   []string{"'penaltyLen' or 'penaltyTime' is required"},
+		},
+		{
+			name:   "positions",
+			modify: func(cfg *Config) { cfg.Positions = []string{"kneeling", PositionProne} },
+			want:   []string{"'positions' has 2 positions for 1 firing lines", "'positions[0]' must be"},
+		},
+		{
+			name:   "unknown format",
+			modify: func(cfg *Config) { cfg.Format = "sprint" },
+			want:   []string{"'format' must be"},
+		},
+		{
+			name:   "pursuit",
+			modify: func(cfg *Config) { cfg.Format = FormatPursuit },
+			want:   []string{"'seeds' is required", "'start' is required for a pursuit"},
+		},
+		{
+			name: "relay teams",
+			modify: func(cfg *Config) {
+				cfg.Format = FormatRelay
+				cfg.Start = Time{must(time.Parse(time.TimeOnly, "14:00:00"))}
+				cfg.Teams = []Team{{ID: 1, Members: []int{11, 12}}, {ID: 1, Members: []int{12}}, {ID: 3}}
+			},
+			want: []string{
+				"'teams[1].id' 1 is used by another team",
+				"'teams[1].members' competitor(12) is already a member of team(1)",
+				"'teams[2].members' must not be empty",
+			},
+		},
+		{
+			name:   "relay without teams",
+			modify: func(cfg *Config) { cfg.Format = FormatRelay },
+			want:   []string{"'start' is required for a relay", "'teams' are required for a relay"},
+		},
+		{
+			name: "roster",
+			modify: func(cfg *Config) {
+				cfg.Categories = []string{"F"}
+				cfg.TeamClassification = &TeamClassification{By: TeamsByNation}
+			},
+			want: []string{"'categories' require a 'roster'", "'teamClassification.best'", "'teamClassification' requires a 'roster'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			assert.Error(t, err)
+			for _, want := range tt.want {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: `"00:00:30"`, want: 30 * time.Second},
+		{input: `"00:00:30.500"`, want: 30*time.Second + 500*time.Millisecond},
+		{input: `"01:02:03.456"`, want: time.Hour + 2*time.Minute + 3*time.Second + 456*time.Millisecond},
+		{input: `"-00:00:10"`, want: -10 * time.Second},
+		{input: `"1m30s"`, want: 90 * time.Second},
+		{input: `"250ms"`, want: 250 * time.Millisecond},
+		{input: `30`, want: 30 * time.Second},
+		{input: `30.5`, want: 30*time.Second + 500*time.Millisecond},
+		{input: `"45"`, want: 45 * time.Second},
+		{input: `null`, want: 0},
+		{input: `"half a minute"`, wantErr: true},
+		{input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var d Duration
+			err := d.UnmarshalJSON([]byte(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, d.Duration)
+		})
+	}
+}
+
+func TestTimeDurationMarshalJSON(t *testing.T) {
+	start := Time{parseTime(t, "15:04:05.000", "12:34:56.789")}
+	b, err := json.Marshal(start)
+	assert.NoError(t, err)
+	assert.Equal(t, `"12:34:56.789"`, string(b))
+
+	b, err = json.Marshal(Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+
+	for _, d := range []time.Duration{0, 30*time.Second + 500*time.Millisecond, -10 * time.Second} {
+		b, err := json.Marshal(Duration{d})
+		assert.NoError(t, err)
+
+		var got Duration
+		assert.NoError(t, json.Unmarshal(b, &got))
+		assert.Equal(t, d, got.Duration, string(b))
+	}
+
+	b, err = json.Marshal(Duration{2*time.Minute + 3*time.Second + 456*time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, `"00:02:03.456"`, string(b))
+
+	// Durations the clock can't hold are written as Go durations and read back unchanged.
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 24*time.Hour - time.Millisecond, want: `"23:59:59.999"`},
+		{d: 24 * time.Hour, want: `"24h0m0s"`},
+		{d: -24 * time.Hour, want: `"-24h0m0s"`},
+		{d: 100*time.Hour + 500*time.Millisecond, want: `"100h0m0.5s"`},
+		{d: 1500 * time.Microsecond, want: `"1.5ms"`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(Duration{tt.d})
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, string(b))
+
+		var got Duration
+		assert.NoError(t, json.Unmarshal(b, &got))
+		assert.Equal(t, tt.d, got.Duration, string(b))
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	for _, path := range []string{"examples/single/config.json", "examples/relay/config.json", "examples/startlist/config.json"} {
+		cfg, err := loadConfig(path)
+		assert.NoError(t, err, path)
+
+		b, err := json.Marshal(cfg)
+		assert.NoError(t, err, path)
+
+		var got Config
+		assert.NoError(t, json.Unmarshal(b, &got), path)
+
+		// Only the exported fields are written, the files the config refers to are not loaded again.
+		// Every field is given in the written config.
+		got.startTimes, got.competitors, got.given = cfg.startTimes, cfg.competitors, cfg.given
+		assert.Equal(t, cfg, got, path)
+	}
+}
+
+func TestConfigStartTolerance(t *testing.T) {
+	tests := []struct {
+		config string
+		want   time.Duration
+	}{
+		{config: `{"startDelta": 30}`, want: 30 * time.Second},
+		{config: `{"startDelta": 30, "startTolerance": null}`, want: 30 * time.Second},
+		{config: `{"startDelta": 30, "startTolerance": "00:00:00"}`, want: 0},
+		{config: `{"startDelta": 30, "startTolerance": "1m"}`, want: time.Minute},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		assert.NoError(t, json.Unmarshal([]byte(tt.config), &cfg), tt.config)
+		assert.Equal(t, tt.want, cfg.startTolerance(), tt.config)
+
+		// The tolerance is the same once the config is printed and read back.
+		b, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		var printed Config
+		assert.NoError(t, json.Unmarshal(b, &printed), string(b))
+		assert.Equal(t, tt.want, printed.startTolerance(), string(b))
+	}
+}
+
+func TestLapLengthsOf(t *testing.T) {
+	assert.Equal(t, 3500, LapLengths{3500}.Of(2))
+	assert.Equal(t, 3400, LapLengths{3300, 3300, 3400}.Of(2))
+	assert.Equal(t, 0, LapLengths{3300, 3300, 3400}.Of(3))
+	assert.Equal(t, 0, LapLengths{}.Of(0))
+}
+
 func parseTime(t *testing.T, format string, s string) time.Time {
 	tt, err := time.Parse(format, s)
 	if err != nil {
@@ -126,3 +465,27 @@ func parseTime(t *testing.T, format string, s string) time.Time {
 	}
 	return tt
 }
+
+func TestLoadConfigRoster(t *testing.T) {
+	cfg, err := loadConfig("examples/startlist/config.json")
+	assert.Nil(t, err)
+	assert.Len(t, cfg.competitors, 8)
+	assert.Equal(t, "Anna Berg", cfg.competitor(1).Name)
+	assert.Equal(t, Competitor{}, cfg.competitor(99))
+
+	tmpfile, err := os.CreateTemp("", "config*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(`{"laps": 1, "lapLen": 1000, "roster": "non_existent_file.csv"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadConfig(tmpfile.Name())
+	assert.ErrorContains(t, err, "loading roster")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile reads the config file and picks the decoder by its extension.
+// YAML (.yaml, .yml) and TOML (.toml) configs are converted to JSON, so that every format
+// is decoded by the same unmarshalers. Any other file is read as JSON.
+func readConfigFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &v)
+	case ".toml":
+		var m map[string]any
+		_, err = toml.Decode(string(data), &m)
+		v = m
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	data, err = json.Marshal(toJSONValue(v))
+	if err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	return data, nil
+}
+
+// toJSONValue converts a decoded YAML or TOML value to a value encoding/json can marshal.
+// Clock times written without quotes, such as TOML local times, become strings in the format of the JSON config.
+func toJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = toJSONValue(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = toJSONValue(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = toJSONValue(value)
+		}
+		return v
+	case []map[string]any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			s[i] = toJSONValue(value)
+		}
+		return s
+	case time.Time:
+		return v.Format("15:04:05.000")
+	default:
+		return v
+	}
+}
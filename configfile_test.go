@@ -0,0 +1,265 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigYAML(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantConfig Config
+	}{
+		{
+			name: "valid config without milliseconds",
+			config: `
+laps: 2
+lapLen: 3651
+penaltyLen: 50
+firingLines: 1
+start: "09:30:00"
+startDelta: "00:00:30"
+`,
+			wantConfig: Config{
+				Laps:        2,
+				LapLen:      LapLengths{3651},
+				PenaltyLen:  50,
+				FiringLines: 1,
+				Start:       Time{parseTime(t, time.TimeOnly, "09:30:00")},
+				StartDelta:  Duration{30 * time.Second},
+			},
+		},
+		{
+			name: "valid config with milliseconds",
+			config: `
+laps: 3
+lapLen: 1000
+penaltyLen: 25
+firingLines: 2
+start: 12:34:56.789
+startDelta: 00:02:03
+`,
+			wantConfig: Config{
+				Laps:        3,
+				LapLen:      LapLengths{1000},
+				PenaltyLen:  25,
+				FiringLines: 2,
+				Start:       Time{parseTime(t, "15:04:05.000", "12:34:56.789")},
+				StartDelta:  Duration{2*time.Minute + 3*time.Second},
+			},
+		},
+		{
+			name: "valid config with lengths per lap",
+			config: `
+laps: 3
+lapLen: [3300, 3300, 3400]
+start: "10:00:00"
+startDelta: "00:00:30"
+`,
+			wantConfig: Config{
+				Laps:       3,
+				LapLen:     LapLengths{3300, 3300, 3400},
+				Start:      Time{parseTime(t, time.TimeOnly, "10:00:00")},
+				StartDelta: Duration{30 * time.Second},
+			},
+		},
+		{
+			name: "invalid number of lap lengths",
+			config: `
+laps: 2
+lapLen: [3300, 3300, 3400]
+`,
+		},
+		{
+			name:   "invalid lapLen format",
+			config: `lapLen: long`,
+		},
+		{
+			name:   "invalid start time format",
+			config: `start: invalid_time`,
+		},
+		{
+			name:   "invalid startDelta format",
+			config: `startDelta: invalid_delta`,
+		},
+		{
+			name:   "invalid YAML syntax",
+			config: "laps: 2\n  lapLen: [",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkLoadConfig(t, "config*.yaml", tt.config, tt.name, tt.wantConfig)
+		})
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantConfig Config
+	}{
+		{
+			name: "valid config without milliseconds",
+			config: `
+laps = 2
+lapLen = 3651
+penaltyLen = 50
+firingLines = 1
+start = "09:30:00"
+startDelta = "00:00:30"
+`,
+			wantConfig: Config{
+				Laps:        2,
+				LapLen:      LapLengths{3651},
+				PenaltyLen:  50,
+				FiringLines: 1,
+				Start:       Time{parseTime(t, time.TimeOnly, "09:30:00")},
+				StartDelta:  Duration{30 * time.Second},
+			},
+		},
+		{
+			name: "valid config with milliseconds",
+			config: `
+laps = 3
+lapLen = 1000
+penaltyLen = 25
+firingLines = 2
+start = 12:34:56.789
+startDelta = "00:02:03"
+`,
+			wantConfig: Config{
+				Laps:        3,
+				LapLen:      LapLengths{1000},
+				PenaltyLen:  25,
+				FiringLines: 2,
+				Start:       Time{parseTime(t, "15:04:05.000", "12:34:56.789")},
+				StartDelta:  Duration{2*time.Minute + 3*time.Second},
+			},
+		},
+		{
+			name: "valid config with lengths per lap",
+			config: `
+laps = 3
+lapLen = [3300, 3300, 3400]
+start = "10:00:00"
+startDelta = "00:00:30"
+`,
+			wantConfig: Config{
+				Laps:       3,
+				LapLen:     LapLengths{3300, 3300, 3400},
+				Start:      Time{parseTime(t, time.TimeOnly, "10:00:00")},
+				StartDelta: Duration{30 * time.Second},
+			},
+		},
+		{
+			name: "invalid number of lap lengths",
+			config: `
+laps = 2
+lapLen = [3300, 3300, 3400]
+`,
+		},
+		{
+			name:   "invalid lapLen format",
+			config: `lapLen = "long"`,
+		},
+		{
+			name:   "invalid start time format",
+			config: `start = "invalid_time"`,
+		},
+		{
+			name:   "invalid startDelta format",
+			config: `startDelta = "invalid_delta"`,
+		},
+		{
+			name:   "invalid TOML syntax",
+			config: `laps = 2 lapLen = "invalid";`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkLoadConfig(t, "config*.toml", tt.config, tt.name, tt.wantConfig)
+		})
+	}
+}
+
+// checkLoadConfig loads the config from a temporary file and checks it the same way as TestLoadConfig.
+func checkLoadConfig(t *testing.T, pattern, config, name string, wantConfig Config) {
+	tmpfile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.WriteString(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadConfig(tmpfile.Name())
+
+	assert := assert.New(t)
+
+	if strings.HasPrefix(name, "valid") {
+		assert.Nil(err)
+		assert.Equal(wantConfig.Laps, got.Laps)
+		assert.Equal(wantConfig.LapLen, got.LapLen)
+		assert.Equal(wantConfig.PenaltyLen, got.PenaltyLen)
+		assert.Equal(wantConfig.FiringLines, got.FiringLines)
+		assert.Equal(wantConfig.Start.Time, got.Start.Time)
+		assert.Equal(wantConfig.StartDelta.Duration, got.StartDelta.Duration)
+	} else {
+		assert.NotNil(err)
+	}
+}
+
+func TestLoadConfigFormatsAgree(t *testing.T) {
+	want, err := loadConfig("examples/single/config.json")
+	assert.Nil(t, err)
+
+	for _, path := range []string{"examples/single/config.yaml", "examples/single/config.toml"} {
+		got, err := loadConfig(path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+}
+
+func TestLoadConfigYAMLCompetitions(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "config*.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	config := `
+laps: 2
+lapLen: 3000
+competitions:
+  - id: women
+  - id: men
+    lapLen: 3500
+`
+	if _, err := tmpfile.WriteString(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(tmpfile.Name())
+	assert.Nil(t, err)
+	assert.Len(t, cfg.Competitions, 2)
+	assert.Equal(t, LapLengths{3000}, cfg.Competitions[0].LapLen)
+	assert.Equal(t, LapLengths{3500}, cfg.Competitions[1].LapLen)
+	assert.Equal(t, 2, cfg.Competitions[1].Laps)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Correction amends the recorded events after the fact.
+// A correction either removes a recorded event or adds a missing one.
+type Correction struct {
+	Remove bool
+	Event  Event
+	Reason string // The comment preceding the correction in the corrections file
+}
+
+func (c Correction) String() string {
+	action := "added"
+	if c.Remove {
+		action = "removed"
+	}
+	s := fmt.Sprintf("[AUDIT] %s %s", action, formatEventLine(c.Event))
+	if c.Reason != "" {
+		s += fmt.Sprintf(" (%s)", c.Reason)
+	}
+	return s
+}
+
+// loadCorrections reads corrections from a file.
+func loadCorrections(path string) ([]Correction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseCorrections(file)
+}
+
+// parseCorrections reads corrections in the format:
+//
+//	# reason applied to the corrections below
+//	- [timestamp] eventID competitorID [extra...]
+//	+ [timestamp] eventID competitorID [extra...]
+//
+// A line starting with "-" removes a recorded event, a line starting with "+" adds a new one.
+func parseCorrections(r io.Reader) ([]Correction, error) {
+	var corrections []Correction
+	var reason string
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var remove bool
+		switch line[0] {
+		case '#':
+			reason = strings.TrimSpace(line[1:])
+			continue
+		case '-':
+			remove = true
+		case '+':
+		default:
+			return nil, fmt.Errorf("line %d: correction must start with '+' or '-': %s", n, line)
+		}
+
+		evt, err := parseEventLine(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		corrections = append(corrections, Correction{Remove: remove, Event: evt, Reason: reason})
+	}
+
+	return corrections, scanner.Err()
+}
+
+// applyCorrections returns a copy of the events with the corrections applied and writes the audit trail.
+// A removal matches the first event with the same timestamp, event ID and competitor ID,
+// extra parameters are compared only if the correction specifies them.
+// An added event is placed after all events with the same or an earlier timestamp.
+func applyCorrections(w io.Writer, events []Event, corrections []Correction) []Event {
+	events = slices.Clone(events)
+
+	for _, c := range corrections {
+		if c.Remove {
+			i := slices.IndexFunc(events, func(e Event) bool { return matchesCorrection(e, c.Event) })
+			if i < 0 {
+				logError(w, "correction", fmt.Errorf("no event matches %s", formatEventLine(c.Event)))
+				continue
+			}
+			events = slices.Delete(events, i, i+1)
+		} else {
+			i := slices.IndexFunc(events, func(e Event) bool { return e.Timestamp.After(c.Event.Timestamp) })
+			if i < 0 {
+				i = len(events)
+			}
+			events = slices.Insert(events, i, c.Event)
+		}
+		fmt.Fprintln(w, c)
+	}
+
+	return events
+}
+
+func matchesCorrection(e, target Event) bool {
+	if !e.Timestamp.Equal(target.Timestamp) || e.ID != target.ID || e.CompetitorID != target.CompetitorID {
+		return false
+	}
+	return len(target.Extra) == 0 || slices.Equal(e.Extra, target.Extra)
+}
+
+// diffRankings writes the competitors whose rank or result changed between two final reports.
+func diffRankings(w io.Writer, before, after []Result) {
+	oldRanks := ranks(before)
+	newRanks := ranks(after)
+
+	oldResults := make(map[int]Result, len(before))
+	for _, r := range before {
+		oldResults[r.CompetitorID] = r
+	}
+
+	var changed bool
+	for _, r := range after {
+		old, ok := oldResults[r.CompetitorID]
+		oldStatus := "-"
+		if ok {
+			oldStatus = old.status()
+		}
+		oldRank, newRank := oldRanks[r.CompetitorID], newRanks[r.CompetitorID]
+		if ok && oldRank == newRank && oldStatus == r.status() {
+			continue
+		}
+
+		changed = true
+		fmt.Fprintf(w, "[DIFF] %d%s rank %s -> %s, %s -> %s\n",
+			r.CompetitorID, r.Competitor.label(), formatRank(oldRank), formatRank(newRank), formatStatus(oldStatus), formatStatus(r.status()))
+	}
+
+	if !changed {
+		fmt.Fprintln(w, "[DIFF] no changes in the ranking")
+	}
+}
+
+func formatRank(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return fmt.Sprint(rank)
+}
+
+// formatStatus makes the empty status of an active competitor visible in the diff.
+func formatStatus(status string) string {
+	if status == "" {
+		return "-"
+	}
+	return status
+}
+
+// runCorrections processes the events twice, before and after applying the corrections,
+// and writes the audit trail, the corrected final report and the changes in the ranking.
+func runCorrections(eventsReader io.Reader, w io.Writer, cfg Config, corrections []Correction) {
+	var events []Event
+	for evt := range parseEvents(eventsReader, w) {
+		events = append(events, evt)
+	}
+
+	before := rankResults(cfg, processEvents(io.Discard, cfg, sendEvents(events)))
+
+	corrected := applyCorrections(w, events, corrections)
+	summary := processEvents(io.Discard, cfg, sendEvents(corrected))
+	generateReport(w, cfg, summary)
+
+	diffRankings(w, before, rankResults(cfg, summary))
+}
+
+// sendEvents sends the buffered events to a channel in the same way parseEvents does.
+func sendEvents(events []Event) chan Event {
+	eventCh := make(chan Event)
+	go func() {
+		defer close(eventCh)
+		for _, evt := range events {
+			eventCh <- evt
+		}
+	}()
+	return eventCh
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCorrections(t *testing.T) {
+	input := `# Misrecorded target
+- [10:00:01.000] 6 1 4
++ [10:00:01.000] 6 1 3
+
++ [10:00:02.000] 16 2 +00:00:10
+`
+	corrections, err := parseCorrections(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, corrections, 3)
+
+	assert.True(t, corrections[0].Remove)
+	assert.Equal(t, "Misrecorded target", corrections[0].Reason)
+	assert.Equal(t, []string{"4"}, corrections[0].Event.Extra)
+	assert.False(t, corrections[1].Remove)
+	assert.Equal(t, EventShotHit, corrections[1].Event.ID)
+	assert.Equal(t, "Misrecorded target", corrections[2].Reason)
+	assert.Equal(t, EventJuryTimePenalty, corrections[2].Event.ID)
+}
+
+func TestParseCorrectionsInvalid(t *testing.T) {
+	_, err := parseCorrections(strings.NewReader("[10:00:01.000] 6 1 4\n"))
+	assert.ErrorContains(t, err, "line 1")
+
+	_, err = parseCorrections(strings.NewReader("- [10:00:01.000] x 1\n"))
+	assert.ErrorContains(t, err, "invalid event id")
+}
+
+func TestApplyCorrections(t *testing.T) {
+	base := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Timestamp: base, ID: EventShotHit, CompetitorID: 1, Extra: []string{"4"}},
+		{Timestamp: base.Add(2 * time.Second), ID: EventShotHit, CompetitorID: 1, Extra: []string{"5"}},
+	}
+	corrections := []Correction{
+		{Remove: true, Event: Event{Timestamp: base, ID: EventShotHit, CompetitorID: 1}, Reason: "wrong target"},
+		{Event: Event{Timestamp: base.Add(time.Second), ID: EventShotHit, CompetitorID: 1, Extra: []string{"3"}}},
+		{Remove: true, Event: Event{Timestamp: base, ID: EventShotHit, CompetitorID: 2}},
+	}
+
+	var out bytes.Buffer
+	got := applyCorrections(&out, events, corrections)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, []string{"3"}, got[0].Extra)
+	assert.Equal(t, []string{"5"}, got[1].Extra)
+	assert.Len(t, events, 2, "the recorded events must not be modified")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "[AUDIT] removed [10:00:00.000] 6 1 (wrong target)", lines[0])
+	assert.Equal(t, "[AUDIT] added [10:00:01.000] 6 1 3", lines[1])
+	assert.Contains(t, lines[2], "[ERROR] correction")
+}
+
+func TestDiffRankings(t *testing.T) {
+	finished := func(id int, d time.Duration) Result {
+		return Result{CompetitorState: &CompetitorState{CompetitorID: id, Status: StatusFinished, TotalRaceDuration: d}}
+	}
+	before := []Result{finished(1, time.Minute), finished(2, 2*time.Minute), finished(3, 3*time.Minute)}
+
+	var out bytes.Buffer
+	diffRankings(&out, before, before)
+	assert.Equal(t, "[DIFF] no changes in the ranking\n", out.String())
+
+	dsq := Result{CompetitorState: &CompetitorState{CompetitorID: 1, Status: StatusDisqualified}}
+	after := []Result{dsq, finished(2, 2*time.Minute), finished(3, 3*time.Minute)}
+
+	out.Reset()
+	diffRankings(&out, before, after)
+	assert.Equal(t, `[DIFF] 1 rank 1 -> -, 00:01:00.000 -> DSQ
+[DIFF] 2 rank 2 -> 1, 00:02:00.000 -> 00:02:00.000
+[DIFF] 3 rank 3 -> 2, 00:03:00.000 -> 00:03:00.000
+`, out.String())
+}
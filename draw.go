@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// parseStartTime parses the start time drawn for the competitor by event 2.
+func parseStartTime(evt Event) (time.Time, error) {
+	if len(evt.Extra) == 0 {
+		return time.Time{}, fmt.Errorf("missing start time")
+	}
+	t, err := time.Parse(time.TimeOnly, evt.Extra[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start time: %w", err)
+	}
+	return t, nil
+}
+
+// checkStartDraw checks that a start time drawn in an interval start falls on the grid of
+// Start + k*StartDelta and that no other competitor has been drawn to the same start time.
+func checkStartDraw(cfg Config, summary Summary, evt Event) error {
+	if cfg.Format != FormatInterval {
+		return nil
+	}
+
+	t, err := parseStartTime(evt)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Start.IsZero() && cfg.StartDelta.Duration > 0 {
+		offset := t.Sub(cfg.Start.Time)
+		if offset < 0 || offset%cfg.StartDelta.Duration != 0 {
+			return fmt.Errorf("start time %s of competitor(%d) is not on the grid starting at %s every %s",
+				t.Format("15:04:05.000"), evt.CompetitorID, cfg.Start.Format("15:04:05.000"), cfg.StartDelta)
+		}
+	}
+
+	for id, st := range summary {
+		if id != evt.CompetitorID && st.ScheduledStartTime.Equal(t) {
+			return fmt.Errorf("start time %s of competitor(%d) has already been drawn for competitor(%d)",
+				t.Format("15:04:05.000"), evt.CompetitorID, id)
+		}
+	}
+
+	return nil
+}
+
+// DrawEntry is a competitor entered into the start list draw.
+// Competitors of a lower seeding group start first, the group is zero if not given.
+type DrawEntry struct {
+	CompetitorID int
+	Group        int
+}
+
+// StartListEntry is a competitor with the start time assigned by the draw.
+type StartListEntry struct {
+	DrawEntry
+	StartTime  time.Time
+	Competitor Competitor
+}
+
+// readDrawEntries reads the competitors entered into the draw, one per line in the format:
+// competitorID [group]. Empty lines and lines starting with '#' are skipped.
+func readDrawEntries(r io.Reader) ([]DrawEntry, error) {
+	var entries []DrawEntry
+	seen := make(map[int]bool)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 || strings.HasPrefix(parts[0], "#") {
+			continue
+		}
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("line %d: expected competitor ID and optional group", n)
+		}
+
+		var entry DrawEntry
+		var err error
+		if entry.CompetitorID, err = strconv.Atoi(parts[0]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid competitor id: %w", n, err)
+		}
+		if len(parts) == 2 {
+			if entry.Group, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid group: %w", n, err)
+			}
+		}
+		if seen[entry.CompetitorID] {
+			return nil, fmt.Errorf("line %d: competitor(%d) is entered twice", n, entry.CompetitorID)
+		}
+		seen[entry.CompetitorID] = true
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// drawStartList draws the start order within every seeding group and assigns start times
+// on the grid of start + k*delta. The same entries and seed always give the same start list,
+// regardless of the order the entries are given in and of the Go release.
+func drawStartList(entries []DrawEntry, start time.Time, delta time.Duration, seed uint64) []StartListEntry {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b DrawEntry) int {
+		return cmp.Or(a.Group-b.Group, a.CompetitorID-b.CompetitorID)
+	})
+
+	pcg := rand.NewPCG(seed, seed)
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].Group == entries[i].Group {
+			j++
+		}
+		group := entries[i:j]
+		shuffle(group, pcg)
+		i = j
+	}
+
+	startList := make([]StartListEntry, len(entries))
+	for i, entry := range entries {
+		startList[i] = StartListEntry{
+			DrawEntry: entry,
+			StartTime: start.Add(time.Duration(i) * delta),
+		}
+	}
+	return startList
+}
+
+// shuffle shuffles the entries with the Fisher-Yates algorithm. The numbers are drawn from the PCG
+// itself, its output is fixed for a seed while the one of rand.Shuffle may change between Go releases.
+func shuffle(entries []DrawEntry, pcg *rand.PCG) {
+	for i := len(entries) - 1; i > 0; i-- {
+		j := uniform(pcg, uint64(i+1))
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+}
+
+// uniform returns a uniformly distributed number in [0, n). The numbers below 2^64 mod n
+// are drawn again, so that every remainder is equally likely.
+func uniform(pcg *rand.PCG, n uint64) uint64 {
+	threshold := -n % n
+	for {
+		if v := pcg.Uint64(); v >= threshold {
+			return v % n
+		}
+	}
+}
+
+// writeStartList writes the printable start list followed by the event 2 lines announcing
+// the drawn start times at the given time.
+func writeStartList(w io.Writer, startList []StartListEntry, at time.Time) {
+	// The names and nations are listed only if the competitors are on the roster.
+	withNames := slices.ContainsFunc(startList, func(e StartListEntry) bool { return e.Competitor.String() != "" })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if withNames {
+		fmt.Fprintln(tw, "No\tBib\tGroup\tStart\tName\tNation")
+	} else {
+		fmt.Fprintln(tw, "No\tBib\tGroup\tStart")
+	}
+	for i, entry := range startList {
+		bib := cmp.Or(entry.Competitor.Bib, entry.CompetitorID)
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s", i+1, bib, entry.Group, entry.StartTime.Format("15:04:05.000"))
+		if withNames {
+			fmt.Fprintf(tw, "\t%s\t%s", entry.Competitor.Name, entry.Competitor.Nation)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	for _, entry := range startList {
+		fmt.Fprintln(w, formatEventLine(Event{
+			Timestamp:    at,
+			ID:           EventSetStartTime,
+			CompetitorID: entry.CompetitorID,
+			Extra:        []string{entry.StartTime.Format("15:04:05.000")},
+		}))
+	}
+}
+
+// runStartList draws the start list of the competitors read from the reader.
+// The start times are announced by default half an hour before the first start.
+func runStartList(entriesReader io.Reader, w io.Writer, cfg Config, seed uint64, at time.Time) error {
+	if cfg.Format != FormatInterval {
+		return fmt.Errorf("start times are not drawn in a %s", cfg.Format)
+	}
+	if len(cfg.Competitions) > 0 {
+		return fmt.Errorf("start lists are drawn for a single competition")
+	}
+	if cfg.Start.IsZero() || cfg.StartDelta.Duration <= 0 {
+		return fmt.Errorf("'start' and 'startDelta' must be set to draw the start list")
+	}
+
+	entries, err := readDrawEntries(entriesReader)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := cfg.competitors[entry.CompetitorID]; cfg.competitors != nil && !ok {
+			return fmt.Errorf("competitor(%d) is not on the roster", entry.CompetitorID)
+		}
+	}
+
+	if at.IsZero() {
+		at = cfg.Start.Add(-30 * time.Minute)
+	}
+	startList := drawStartList(entries, cfg.Start.Time, cfg.StartDelta.Duration, seed)
+	for i := range startList {
+		startList[i].Competitor = cfg.competitor(startList[i].CompetitorID)
+	}
+	writeStartList(w, startList, at)
+	return nil
+}
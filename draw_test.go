@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStartDraw(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "10:00:00"))
+	cfg := Config{Start: Time{start}, StartDelta: Duration{30 * time.Second}}
+	summary := Summary{
+		1: {CompetitorID: 1, ScheduledStartTime: start},
+		2: {CompetitorID: 2},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		evt     Event
+		wantErr string
+	}{
+		{name: "on the grid", cfg: cfg, evt: Event{CompetitorID: 2, Extra: []string{"10:01:30"}}},
+		{name: "redraw of the same competitor", cfg: cfg, evt: Event{CompetitorID: 1, Extra: []string{"10:00:00"}}},
+		{name: "off the grid", cfg: cfg, evt: Event{CompetitorID: 2, Extra: []string{"10:00:10"}}, wantErr: "not on the grid"},
+		{name: "before the start", cfg: cfg, evt: Event{CompetitorID: 2, Extra: []string{"09:59:30"}}, wantErr: "not on the grid"},
+		{name: "duplicate", cfg: cfg, evt: Event{CompetitorID: 2, Extra: []string{"10:00:00"}}, wantErr: "already been drawn for competitor(1)"},
+		{name: "missing start time", cfg: cfg, evt: Event{CompetitorID: 2}, wantErr: "missing start time"},
+		{name: "no grid without start", cfg: Config{StartDelta: Duration{30 * time.Second}}, evt: Event{CompetitorID: 2, Extra: []string{"10:00:10"}}},
+		{name: "pursuit", cfg: Config{Format: FormatPursuit, Start: Time{start}, StartDelta: Duration{30 * time.Second}}, evt: Event{CompetitorID: 2, Extra: []string{"10:00:00"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStartDraw(tt.cfg, summary, tt.evt)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadDrawEntries(t *testing.T) {
+	entries, err := readDrawEntries(strings.NewReader("# bib group\n3 1\n\n1\n2 2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []DrawEntry{{CompetitorID: 3, Group: 1}, {CompetitorID: 1}, {CompetitorID: 2, Group: 2}}, entries)
+
+	_, err = readDrawEntries(strings.NewReader("1\n1 2\n"))
+	assert.ErrorContains(t, err, "entered twice")
+
+	_, err = readDrawEntries(strings.NewReader("1 x\n"))
+	assert.ErrorContains(t, err, "invalid group")
+
+	_, err = readDrawEntries(strings.NewReader("1 2 3\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestDrawStartList(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "10:00:00"))
+	entries := []DrawEntry{{1, 2}, {2, 1}, {3, 2}, {4, 1}, {5, 2}, {6, 1}}
+
+	startList := drawStartList(entries, start, 30*time.Second, 42)
+	require.Len(t, startList, len(entries))
+
+	for i, entry := range startList {
+		assert.Equal(t, start.Add(time.Duration(i)*30*time.Second), entry.StartTime)
+		if i < 3 {
+			assert.Equal(t, 1, entry.Group, "the first group starts first")
+		} else {
+			assert.Equal(t, 2, entry.Group)
+		}
+	}
+
+	// The draw is reproducible and independent of the order of the entries.
+	reversed := slices.Clone(entries)
+	slices.Reverse(reversed)
+	assert.Equal(t, startList, drawStartList(reversed, start, 30*time.Second, 42))
+}
+
+func TestDrawStartListSeed(t *testing.T) {
+	var entries []DrawEntry
+	for id := 1; id <= 10; id++ {
+		entries = append(entries, DrawEntry{CompetitorID: id})
+	}
+
+	// A published seed reproduces the same start order with every Go release.
+	var order []int
+	for _, entry := range drawStartList(entries, time.Time{}, time.Second, 42) {
+		order = append(order, entry.CompetitorID)
+	}
+	assert.Equal(t, []int{5, 9, 4, 3, 10, 8, 2, 7, 6, 1}, order)
+}
+
+func TestRunStartList(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "10:00:00"))
+	cfg := Config{Start: Time{start}, StartDelta: Duration{time.Minute}}
+
+	var out bytes.Buffer
+	require.NoError(t, runStartList(strings.NewReader("7\n"), &out, cfg, 0, time.Time{}))
+	assert.Equal(t, "No  Bib  Group  Start\n1   7    0      10:00:00.000\n\n[09:30:00.000] 2 7 10:00:00.000\n", out.String())
+
+	assert.Error(t, runStartList(strings.NewReader("7\n"), &out, Config{Format: FormatMass}, 0, time.Time{}))
+	assert.Error(t, runStartList(strings.NewReader("7\n"), &out, Config{}, 0, time.Time{}))
+}
@@ -6,7 +6,7 @@ import (
 	"time"
 )
 
-// Incoming events (1-11)
+// Incoming events (1-17)
 // These constants are used to identify and handle specific actions or states of competitors.
 const (
 	_                        = iota
@@ -21,19 +21,68 @@ const (
 	EventFinishedPenaltyLaps // A competitor has finished penalty laps
 	EventFinishedLap         // A competitor has completed a main lap
 	EventCantContinue        // A competitor cannot continue the race
+	EventHandover            // A competitor has taken over the relay from the previous leg
+	EventSpareLoaded         // A competitor has loaded a spare round by hand
+	EventShotFired           // A competitor has fired a shot
+	EventJuryDisqualified    // The jury has disqualified a competitor
+	EventJuryTimePenalty     // The jury has given a competitor a time penalty
+	EventJuryReinstated      // The jury has reinstated a disqualified competitor
 
-	// Outgoing events (32-33)
+	// Outgoing events (32-34)
 	// These constants represent events that are sent out as a result of certain actions or states.
-	EventDisqualified = iota + 20 // A competitor has been disqualified
+	EventDisqualified = iota + 14 // A competitor has been disqualified
 	EventFinishedRace             // A competitor has finished the race
+	EventLapped                   // A competitor has been lapped and pulled from the course
 )
 
+// EventSpec describes an incoming event in the event catalog.
+type EventSpec struct {
+	ID          int
+	Params      string // Extra parameters, optional ones in brackets
+	Description string
+	pattern     string // Regular expression of the extra parameters following the competitor ID
+}
+
+// Patterns of the extra parameters.
+const (
+	clockPattern   = `\d{2}:\d{2}:\d{2}(\.\d+)?`
+	commentPattern = `(\s+\S.*)?`
+)
+
+// eventCatalog lists the incoming events with their extra parameters.
+var eventCatalog = []EventSpec{
+	{EventRegistered, "", "The competitor registered", ""},
+	{EventSetStartTime, "startTime", "The start time was set by a draw", `\s+` + clockPattern},
+	{EventOnStartLine, "", "The competitor is on the start line", ""},
+	{EventStartedRace, "", "The competitor has started", ""},
+	{EventStartedFiringRange, "firingRange [lane]", "The competitor is on the firing range", `\s+\d+(\s+\d+)?`},
+	{EventShotHit, "target", "The target has been hit", `\s+\d+`},
+	{EventFinishedFiringRange, "", "The competitor left the firing range", ""},
+	{EventStartedPenaltyLaps, "", "The competitor entered the penalty laps", ""},
+	{EventFinishedPenaltyLaps, "", "The competitor left the penalty laps", ""},
+	{EventFinishedLap, "", "The competitor ended the main lap", ""},
+	{EventCantContinue, "[comment]", "The competitor can't continue", commentPattern},
+	{EventHandover, "", "The competitor has taken over the relay", ""},
+	{EventSpareLoaded, "", "The competitor loaded a spare round", ""},
+	{EventShotFired, "", "The competitor fired a shot", ""},
+	{EventJuryDisqualified, "code [reason]", "The jury disqualified the competitor", `\s+\S+` + commentPattern},
+	{EventJuryTimePenalty, "time [comment]", "The jury gave the competitor a time penalty", `\s+[+-]?` + clockPattern + commentPattern},
+	{EventJuryReinstated, "[comment]", "The jury reinstated the competitor", commentPattern},
+}
+
 // Event represents an event that occurs during the competition.
 type Event struct {
 	Timestamp    time.Time // The time when the event occurred
 	ID           int
 	CompetitorID int
 	Extra        []string // Additional information related to the event
+	Competition  string   // The competition the event belongs to if given explicitly
+}
+
+// isJuryEvent reports whether the event is a jury decision.
+// Jury decisions apply to competitors that are no longer on the course as well.
+func isJuryEvent(id int) bool {
+	return id == EventJuryDisqualified || id == EventJuryTimePenalty || id == EventJuryReinstated
 }
 
 // returns a human-readable string representation of an event.
@@ -63,10 +112,31 @@ func (e Event) String() string {
 	case EventCantContinue:
 		comment := strings.Join(e.Extra, " ")
 		return fmt.Sprintf("[%s] The competitor(%d) can't continue: %s", ts, e.CompetitorID, comment)
+	case EventHandover:
+		return fmt.Sprintf("[%s] The competitor(%d) has taken over the relay", ts, e.CompetitorID)
+	case EventSpareLoaded:
+		return fmt.Sprintf("[%s] The competitor(%d) loaded a spare round", ts, e.CompetitorID)
+	case EventShotFired:
+		return fmt.Sprintf("[%s] The competitor(%d) fired a shot", ts, e.CompetitorID)
+	case EventJuryDisqualified:
+		reason := strings.Join(e.Extra, " ")
+		return fmt.Sprintf("[%s] The jury disqualified the competitor(%d): %s", ts, e.CompetitorID, reason)
+	case EventJuryTimePenalty:
+		comment := strings.Join(e.Extra, " ")
+		return fmt.Sprintf("[%s] The jury gave the competitor(%d) a time penalty: %s", ts, e.CompetitorID, comment)
+	case EventJuryReinstated:
+		comment := strings.Join(e.Extra, " ")
+		return fmt.Sprintf("[%s] The jury reinstated the competitor(%d): %s", ts, e.CompetitorID, comment)
 	case EventDisqualified:
+		if len(e.Extra) > 0 {
+			reason := strings.Join(e.Extra, " ")
+			return fmt.Sprintf("[%s] The competitor(%d) is disqualified: %s", ts, e.CompetitorID, reason)
+		}
 		return fmt.Sprintf("[%s] The competitor(%d) is disqualified", ts, e.CompetitorID)
 	case EventFinishedRace:
 		return fmt.Sprintf("[%s] The competitor(%d) has finished", ts, e.CompetitorID)
+	case EventLapped:
+		return fmt.Sprintf("[%s] The competitor(%d) is lapped and pulled from the course", ts, e.CompetitorID)
 	default:
 		return fmt.Sprintf("[%s] IMPOSSIBLE EVENT %d for competitor(%d)", ts, e.ID, e.CompetitorID)
 	}
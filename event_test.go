@@ -7,6 +7,18 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestEventIDs(t *testing.T) {
+	assert.Equal(t, 12, EventHandover)
+	assert.Equal(t, 13, EventSpareLoaded)
+	assert.Equal(t, 14, EventShotFired)
+	assert.Equal(t, 15, EventJuryDisqualified)
+	assert.Equal(t, 16, EventJuryTimePenalty)
+	assert.Equal(t, 17, EventJuryReinstated)
+	assert.Equal(t, 32, EventDisqualified)
+	assert.Equal(t, 33, EventFinishedRace)
+	assert.Equal(t, 34, EventLapped)
+}
+
 func TestEventString(t *testing.T) {
 	fixedTime := time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC)
 	tests := []struct {
@@ -117,6 +129,63 @@ func TestEventString(t *testing.T) {
 			},
 			expected: "[09:30:00.000] The competitor(11) can't continue: Lost equipment",
 		},
+		{
+			name: "EventHandover",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventHandover,
+				CompetitorID: 16,
+			},
+			expected: "[09:30:00.000] The competitor(16) has taken over the relay",
+		},
+		{
+			name: "EventSpareLoaded",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventSpareLoaded,
+				CompetitorID: 17,
+			},
+			expected: "[09:30:00.000] The competitor(17) loaded a spare round",
+		},
+		{
+			name: "EventShotFired",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventShotFired,
+				CompetitorID: 18,
+			},
+			expected: "[09:30:00.000] The competitor(18) fired a shot",
+		},
+		{
+			name: "EventJuryDisqualified",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventJuryDisqualified,
+				CompetitorID: 19,
+				Extra:        []string{"R7", "missed", "penalty", "loop"},
+			},
+			expected: "[09:30:00.000] The jury disqualified the competitor(19): R7 missed penalty loop",
+		},
+		{
+			name: "EventJuryTimePenalty",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventJuryTimePenalty,
+				CompetitorID: 20,
+				Extra:        []string{"00:01:00", "missed", "penalty", "loop"},
+			},
+			expected: "[09:30:00.000] The jury gave the competitor(20) a time penalty: 00:01:00 missed penalty loop",
+		},
+		{
+			name: "EventJuryReinstated",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventJuryReinstated,
+				CompetitorID: 21,
+				Extra:        []string{"protest", "accepted"},
+			},
+			expected: "[09:30:00.000] The jury reinstated the competitor(21): protest accepted",
+		},
 		{
 			name: "EventDisqualified",
 			event: Event{
@@ -126,6 +195,16 @@ func TestEventString(t *testing.T) {
 			},
 			expected: "[09:30:00.000] The competitor(12) is disqualified",
 		},
+		{
+			name: "EventDisqualifiedWithReason",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventDisqualified,
+				CompetitorID: 12,
+				Extra:        []string{"late", "start"},
+			},
+			expected: "[09:30:00.000] The competitor(12) is disqualified: late start",
+		},
 		{
 			name: "EventFinishedRace",
 			event: Event{
@@ -135,6 +214,15 @@ func TestEventString(t *testing.T) {
 			},
 			expected: "[09:30:00.000] The competitor(13) has finished",
 		},
+		{
+			name: "EventLapped",
+			event: Event{
+				Timestamp:    fixedTime,
+				ID:           EventLapped,
+				CompetitorID: 15,
+			},
+			expected: "[09:30:00.000] The competitor(15) is lapped and pulled from the course",
+		},
 		{
 			name: "UnknownEvent",
 			event: Event{
@@ -2,10 +2,13 @@ module github.com/artem-burashnikov/goathlon
 
 go 1.24.2
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
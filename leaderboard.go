@@ -0,0 +1,230 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Standing is the place of a competitor, or of a team in a relay, on the live leaderboard.
+// Competitors are ranked by the number of checkpoints passed, ending a main lap or leaving
+// the firing range, and then by the time they passed the last one.
+type Standing struct {
+	Rank        int      `json:"rank,omitempty"` // Zero before the first checkpoint and once out of the race
+	ID          int      `json:"id"`             // The competitor ID, or the team ID in a relay
+	Name        string   `json:"name,omitempty"`
+	Status      string   `json:"status,omitempty"` // DSQ, DNF, LAP, DNS or UNF once out of the race
+	Finished    bool     `json:"finished,omitempty"`
+	Checkpoints int      `json:"checkpoints"`
+	Laps        int      `json:"laps"`
+	Misses      int      `json:"misses"`
+	Time        Duration `json:"time"`   // Time at the last checkpoint as the final report counts it, the final time once finished
+	Behind      Duration `json:"behind"` // Gap to the leader at the same checkpoint
+}
+
+// Snapshot holds the standings of a competition at a moment of the race.
+type Snapshot struct {
+	Competition string     `json:"competition,omitempty"`
+	Time        Time       `json:"time"`  // The time of the event that changed the standings
+	Final       bool       `json:"final"` // Whether all events of the competition have been processed
+	Standings   []Standing `json:"standings"`
+}
+
+// Leaderboard holds the latest snapshots of the competitions while their events are processed.
+// It may be queried from other goroutines, e.g. by serving it over HTTP.
+type Leaderboard struct {
+	mu        sync.RWMutex
+	snapshots []Snapshot // In the order the competitions were first published
+}
+
+func newLeaderboard() *Leaderboard {
+	return &Leaderboard{}
+}
+
+// publish replaces the snapshot of the competition. A published snapshot is never modified.
+func (lb *Leaderboard) publish(s Snapshot) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	i := slices.IndexFunc(lb.snapshots, func(other Snapshot) bool { return other.Competition == s.Competition })
+	if i < 0 {
+		lb.snapshots = append(lb.snapshots, s)
+		return
+	}
+	lb.snapshots[i] = s
+}
+
+// Snapshot returns the latest snapshot of the competition, the ID is empty for a single competition.
+func (lb *Leaderboard) Snapshot(competition string) (Snapshot, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	i := slices.IndexFunc(lb.snapshots, func(s Snapshot) bool { return s.Competition == competition })
+	if i < 0 {
+		return Snapshot{}, false
+	}
+	return lb.snapshots[i], true
+}
+
+// Snapshots returns the latest snapshots of all competitions.
+func (lb *Leaderboard) Snapshots() []Snapshot {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	return slices.Clone(lb.snapshots)
+}
+
+// ServeHTTP writes the snapshots of all competitions as JSON,
+// or the snapshot of a single one if it is given by the competition query parameter.
+func (lb *Leaderboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var v any = lb.Snapshots()
+	if r.URL.Query().Has("competition") {
+		s, ok := lb.Snapshot(r.URL.Query().Get("competition"))
+		if !ok {
+			http.Error(w, "unknown competition", http.StatusNotFound)
+			return
+		}
+		v = s
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// entrant is a competitor, or a relay team, ranked on the leaderboard.
+type entrant struct {
+	id          int
+	name        string
+	status      CompetitorStatus
+	start       time.Time // The actual start, of the first leg in a relay
+	checkpoints []Checkpoint
+	laps        int
+	misses      int
+	final       time.Duration // The final time of a finished competitor in an interval start
+	penalty     time.Duration // The time penalties given by the jury, to all legs in a relay
+}
+
+// key returns the time the entrant is ranked by at the given checkpoint, counted from one.
+// The first competitor across the line leads unless the competitors start at intervals.
+// Time penalties given by the jury move the finish, as in the final report.
+func (e entrant) key(cfg Config, checkpoint int) time.Duration {
+	finish := checkpoint == len(e.checkpoints) && e.status == StatusFinished
+	if cfg.ranksByFinishTime() {
+		key := e.checkpoints[checkpoint-1].Time.Sub(cfg.Start.Time)
+		if finish {
+			key += e.penalty
+		}
+		return key
+	}
+	if finish {
+		return e.final
+	}
+	return e.checkpoints[checkpoint-1].RaceTime
+}
+
+// ranked reports whether the entrant is in the race and has passed a checkpoint.
+func (e entrant) ranked() bool {
+	return (e.status == StatusActive || e.status == StatusFinished) && len(e.checkpoints) > 0
+}
+
+// entrants returns the competitors, or the relay teams, of the competition.
+func entrants(cfg Config, summary Summary) []entrant {
+	if cfg.Format != FormatRelay {
+		result := make([]entrant, 0, len(summary))
+		for _, st := range summary {
+			result = append(result, entrant{
+				id:          st.CompetitorID,
+				name:        cfg.competitor(st.CompetitorID).String(),
+				status:      st.Status,
+				start:       st.ActualStartTime,
+				checkpoints: st.Checkpoints,
+				laps:        st.completedLaps(),
+				misses:      st.TotalMisses,
+				final:       st.TotalRaceDuration,
+				penalty:     st.TimePenalty,
+			})
+		}
+		return result
+	}
+
+	// A team passes the checkpoints of its legs one after another and is out of the race with any of them.
+	// The checkpoints are named after the leg, e.g. "Leg 2 Lap 1".
+	result := make([]entrant, 0, len(cfg.Teams))
+	for _, team := range cfg.Teams {
+		e := entrant{id: team.ID, name: teamNation(cfg, team), status: StatusFinished}
+		for leg, member := range team.Members {
+			st, exists := summary[member]
+			if !exists {
+				if e.status == StatusFinished {
+					e.status = StatusActive
+				}
+				continue
+			}
+			if leg == 0 {
+				e.start = st.ActualStartTime
+			}
+			for _, c := range st.Checkpoints {
+				e.checkpoints = append(e.checkpoints, Checkpoint{Name: fmt.Sprintf("Leg %d %s", leg+1, c.Name), Time: c.Time})
+			}
+			e.laps += st.completedLaps()
+			e.misses += st.TotalMisses
+			e.penalty += st.TimePenalty
+			if e.status == StatusFinished {
+				e.status = st.Status
+			}
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// liveStandings ranks the competitors by their progress in the race.
+// Competitors out of the race, or who haven't passed a checkpoint yet, follow unranked.
+func liveStandings(cfg Config, summary Summary) []Standing {
+	all := entrants(cfg, summary)
+	slices.SortFunc(all, func(a, b entrant) int {
+		switch {
+		case a.ranked() != b.ranked():
+			if a.ranked() {
+				return -1
+			}
+			return 1
+		case !a.ranked():
+			return cmp.Or(cmp.Compare(a.status, b.status), cmp.Compare(a.id, b.id))
+		case len(a.checkpoints) != len(b.checkpoints):
+			return cmp.Compare(len(b.checkpoints), len(a.checkpoints))
+		default:
+			n := len(a.checkpoints)
+			return cmp.Or(cmp.Compare(a.key(cfg, n), b.key(cfg, n)), cmp.Compare(a.id, b.id))
+		}
+	})
+
+	standings := make([]Standing, len(all))
+	for i, e := range all {
+		s := Standing{
+			ID:          e.id,
+			Name:        e.name,
+			Status:      e.status.String(),
+			Finished:    e.status == StatusFinished,
+			Checkpoints: len(e.checkpoints),
+			Laps:        e.laps,
+			Misses:      e.misses,
+		}
+		if e.ranked() {
+			leader, n := all[0], len(e.checkpoints)
+			s.Rank = i + 1
+			s.Time = Duration{e.key(cfg, n)}
+			if cfg.ranksByFinishTime() {
+				s.Time = Duration{e.key(cfg, n) - e.start.Sub(cfg.Start.Time)}
+			}
+			s.Behind = Duration{e.key(cfg, n) - leader.key(cfg, n)}
+		}
+		standings[i] = s
+	}
+	return standings
+}
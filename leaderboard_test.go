@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// processLines processes the event lines and returns the snapshot published after each of them.
+func processLines(t *testing.T, cfg Config, lines string) []Snapshot {
+	board := newLeaderboard()
+	p := newProcessor(cfg)
+	p.board = board
+
+	var snapshots []Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(lines), "\n") {
+		evt, err := parseEventLine(line)
+		assert.NoError(t, err)
+		p.process(io.Discard, evt)
+		s, _ := board.Snapshot(cfg.ID)
+		snapshots = append(snapshots, s)
+	}
+	p.finish()
+	s, _ := board.Snapshot(cfg.ID)
+	return append(snapshots, s)
+}
+
+func ranking(s Snapshot) []int {
+	var ids []int
+	for _, standing := range s.Standings {
+		if standing.Rank > 0 {
+			ids = append(ids, standing.ID)
+		}
+	}
+	return ids
+}
+
+func TestLiveStandings(t *testing.T) {
+	cfg := Config{
+		Laps:        2,
+		LapLen:      LapLengths{1000},
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       Time{parseTime(t, time.TimeOnly, "10:00:00")},
+		StartDelta:  Duration{time.Minute},
+	}
+	snapshots := processLines(t, cfg, `
+[09:55:00.000] 1 1
+[09:55:00.000] 1 2
+[09:55:00.000] 2 1 10:00:00.000
+[09:55:00.000] 2 2 10:01:00.000
+[10:00:00.000] 4 1
+[10:01:00.000] 4 2
+[10:05:00.000] 5 1 1
+[10:05:30.000] 7 1
+[10:06:10.000] 5 2 1
+[10:06:20.000] 7 2
+[10:08:00.000] 10 1
+[10:14:00.000] 10 1
+`)
+
+	// Nothing has been published before the first checkpoint.
+	assert.Empty(t, snapshots[5].Standings)
+
+	// Competitor 1 leaves the range first and leads until competitor 2 is faster at the same checkpoint.
+	after1 := snapshots[7]
+	assert.Equal(t, "10:05:30", after1.Time.Format(time.TimeOnly))
+	assert.Equal(t, []int{1}, ranking(after1))
+	assert.Equal(t, 0, after1.Standings[1].Rank)
+
+	after2 := snapshots[9]
+	assert.Equal(t, []int{2, 1}, ranking(after2))
+	assert.Equal(t, 5*time.Minute+20*time.Second, after2.Standings[0].Time.Duration)
+	assert.Equal(t, 5*time.Minute+30*time.Second, after2.Standings[1].Time.Duration)
+	assert.Equal(t, 10*time.Second, after2.Standings[1].Behind.Duration)
+
+	// Ending the lap puts competitor 1 ahead, competitor 2 is still 10 seconds faster at the range.
+	lap := snapshots[10]
+	assert.Equal(t, []int{1, 2}, ranking(lap))
+	assert.Equal(t, 2, lap.Standings[0].Checkpoints)
+	assert.Equal(t, 1, lap.Standings[0].Laps)
+	assert.Equal(t, -10*time.Second, lap.Standings[1].Behind.Duration)
+
+	// Competitor 2 never finishes and drops out of the ranking once all events have been processed.
+	final := snapshots[len(snapshots)-1]
+	assert.True(t, final.Final)
+	assert.Equal(t, "10:14:00", final.Time.Format(time.TimeOnly))
+	assert.Equal(t, []int{1}, ranking(final))
+	assert.True(t, final.Standings[0].Finished)
+	assert.Equal(t, 14*time.Minute, final.Standings[0].Time.Duration)
+	assert.Equal(t, "UNF", final.Standings[1].Status)
+}
+
+func TestLiveStandingsTimePenalty(t *testing.T) {
+	cfg := Config{
+		Laps:   1,
+		LapLen: LapLengths{1000},
+		Format: FormatMass,
+		Start:  Time{parseTime(t, time.TimeOnly, "12:00:00")},
+	}
+	lines := `
+[11:50:00.000] 1 1
+[11:50:00.000] 1 2
+[12:00:00.000] 4 1
+[12:00:00.000] 4 2
+[12:10:00.000] 10 1
+[12:10:05.000] 10 2
+[12:15:00.000] 16 1 00:01:00 Shortcut
+`
+	snapshots := processLines(t, cfg, lines)
+
+	// Competitor 1 crossed the line first, the jury's time penalty puts competitor 2 ahead.
+	final := snapshots[len(snapshots)-1]
+	assert.Equal(t, []int{2, 1}, ranking(final))
+	assert.Equal(t, 11*time.Minute, final.Standings[1].Time.Duration)
+	assert.Equal(t, 55*time.Second, final.Standings[1].Behind.Duration)
+
+	// The final standings agree with the final report.
+	var report []int
+	summary := processEvents(io.Discard, cfg, parseEvents(strings.NewReader(lines), io.Discard))
+	for _, r := range rankResults(cfg, summary) {
+		report = append(report, r.CompetitorID)
+	}
+	assert.Equal(t, report, ranking(final))
+}
+
+func TestLiveStandingsIntervalStart(t *testing.T) {
+	cfg := Config{
+		Laps:        1,
+		LapLen:      LapLengths{1000},
+		FiringLines: 1,
+		PenaltyTime: Duration{time.Minute},
+		Start:       Time{parseTime(t, time.TimeOnly, "10:00:00")},
+		StartDelta:  Duration{time.Minute},
+	}
+	lines := `
+[09:55:00.000] 1 1
+[09:55:00.000] 1 2
+[09:55:00.000] 2 1 10:00:00.000
+[09:55:00.000] 2 2 10:01:00.000
+[10:00:20.000] 4 1
+[10:01:00.000] 4 2
+[10:03:00.000] 5 1 1
+[10:03:05.000] 6 1 1
+[10:03:10.000] 6 1 2
+[10:03:15.000] 6 1 3
+[10:03:20.000] 6 1 4
+[10:03:30.000] 7 1
+[10:04:00.000] 5 2 1
+[10:04:05.000] 6 2 1
+[10:04:10.000] 6 2 2
+[10:04:15.000] 6 2 3
+[10:04:20.000] 6 2 4
+[10:04:22.000] 6 2 5
+[10:04:25.000] 7 2
+[10:08:00.000] 10 1
+[10:09:30.000] 10 2
+`
+	snapshots := processLines(t, cfg, lines)
+
+	// Competitor 1 left the range 3:10 after the actual start, but the late start
+	// and the penalty time for the missed target count: 4:30 against 3:25.
+	after := snapshots[18]
+	assert.Equal(t, []int{2, 1}, ranking(after))
+	assert.Equal(t, 3*time.Minute+25*time.Second, after.Standings[0].Time.Duration)
+	assert.Equal(t, 4*time.Minute+30*time.Second, after.Standings[1].Time.Duration)
+
+	final := snapshots[len(snapshots)-1]
+	assert.Equal(t, []int{2, 1}, ranking(final))
+	assert.Equal(t, 9*time.Minute, final.Standings[1].Time.Duration)
+
+	// The final standings agree with the final report.
+	var report []int
+	summary := processEvents(io.Discard, cfg, parseEvents(strings.NewReader(lines), io.Discard))
+	for _, r := range rankResults(cfg, summary) {
+		report = append(report, r.CompetitorID)
+	}
+	assert.Equal(t, report, ranking(final))
+}
+
+func TestLiveStandingsFinal(t *testing.T) {
+	tests := []struct {
+		dir    string
+		want   []int
+		behind time.Duration
+	}{
+		{dir: "examples/multiple", want: []int{2, 1, 3, 4, 5}, behind: 7*time.Second + 691*time.Millisecond},
+		{dir: "examples/relay", want: []int{1, 2}, behind: 100 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			cfg, err := loadConfig(tt.dir + "/config.json")
+			assert.NoError(t, err)
+			events, err := os.Open(tt.dir + "/events")
+			assert.NoError(t, err)
+			defer events.Close()
+
+			board := newLeaderboard()
+			processEventsLive(io.Discard, cfg, parseEvents(events, io.Discard), board)
+
+			final, ok := board.Snapshot("")
+			assert.True(t, ok)
+			assert.True(t, final.Final)
+			assert.Equal(t, tt.want, ranking(final))
+			assert.Equal(t, tt.behind, final.Standings[1].Behind.Duration)
+		})
+	}
+}
+
+func TestLeaderboardConcurrentReads(t *testing.T) {
+	cfg, err := loadConfig("examples/multiple/config.json")
+	assert.NoError(t, err)
+	events, err := os.Open("examples/multiple/events")
+	assert.NoError(t, err)
+	defer events.Close()
+
+	board := newLeaderboard()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		processEventsLive(io.Discard, cfg, parseEvents(events, io.Discard), board)
+	}()
+
+	// The standings may be read while the events are still being processed.
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		for _, s := range board.Snapshots() {
+			for i, standing := range s.Standings {
+				if standing.Rank > 0 {
+					assert.Equal(t, i+1, standing.Rank)
+				}
+			}
+		}
+	}
+
+	final, _ := board.Snapshot("")
+	assert.True(t, final.Final)
+}
+
+func TestLeaderboardServeHTTP(t *testing.T) {
+	board := newLeaderboard()
+	board.publish(Snapshot{Competition: "women", Standings: []Standing{{Rank: 1, ID: 3, Time: Duration{90 * time.Second}}}})
+	board.publish(Snapshot{Competition: "men"})
+
+	rec := httptest.NewRecorder()
+	board.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var all []Snapshot
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &all))
+	assert.Len(t, all, 2)
+	assert.Equal(t, "women", all[0].Competition)
+
+	rec = httptest.NewRecorder()
+	board.ServeHTTP(rec, httptest.NewRequest("GET", "/?competition=women", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"standings":[{"rank":1,"id":3,"checkpoints":0,"laps":0,"misses":0,"time":"00:01:30.000","behind":"00:00:00.000"}]`)
+
+	rec = httptest.NewRecorder()
+	board.ServeHTTP(rec, httptest.NewRequest("GET", "/?competition=juniors", nil))
+	assert.Equal(t, 404, rec.Code)
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
+	"maps"
+	"net/http"
 	"os"
+	"time"
 )
 
 func must[T any](obj T, err error) T {
@@ -14,19 +20,135 @@ func must[T any](obj T, err error) T {
 }
 
 func run(eventsReader io.Reader, logWriter io.Writer, cfg Config) {
+	runLive(eventsReader, logWriter, cfg, nil)
+}
+
+// runLive runs the competition and publishes the standings to the leaderboard while the events are processed.
+func runLive(eventsReader io.Reader, logWriter io.Writer, cfg Config, board *Leaderboard) {
+	if len(cfg.Competitions) > 0 {
+		runCompetitions(eventsReader, logWriter, cfg, board)
+		return
+	}
+
 	eventCh := parseEvents(eventsReader, logWriter)
-	competitionSummary := processEvents(logWriter, cfg, eventCh)
+	competitionSummary := processEventsLive(logWriter, cfg, eventCh, board)
 	generateReport(logWriter, cfg, competitionSummary)
 }
 
-func main() {
-	cfgPath := os.Getenv("CONFIG_PATH")
+// usageError is a mistake in the command line.
+type usageError string
 
-	cfg := must(loadConfig(cfgPath))
+func (e usageError) Error() string {
+	return string(e)
+}
 
-	in := bufio.NewReader(os.Stdin)
+func main() {
 	out := bufio.NewWriter(os.Stdout)
-	defer out.Flush()
+	err := runCommand(os.Args[1:], bufio.NewReader(os.Stdin), out)
+	if flushErr := out.Flush(); flushErr != nil {
+		panic(flushErr)
+	}
+
+	// Mistakes in the command line exit with status 2, like the flags do.
+	var usage usageError
+	switch {
+	case errors.As(err, &usage):
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "goathlon:", err)
+		os.Exit(1)
+	}
+}
+
+// runCommand runs the command given by the arguments, the competition by default.
+func runCommand(args []string, in io.Reader, out io.Writer) error {
+	flagOverrides := make(Overrides)
+	flags := flag.NewFlagSet("goathlon", flag.ExitOnError)
+	flagOverrides.registerFlags(flags)
+	live := flags.String("live", "", "address to serve the live leaderboard on while the events are processed, e.g. :8080")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	// The season standings are made from results files and the schemas from the types, they don't need a config.
+	// Config fields are overridden by the environment, and the environment by the flags before the command.
+	var cfg Config
+	if len(args) == 0 || args[0] != "season" && args[0] != "schema" {
+		overrides, err := envOverrides(os.Environ())
+		if err != nil {
+			return err
+		}
+		maps.Copy(overrides, flagOverrides)
+
+		cfgPath := os.Getenv("CONFIG_PATH")
+		cfg = must(loadConfigWith(cfgPath, overrides))
+	}
+
+	if len(args) == 0 {
+		var board *Leaderboard
+		if *live != "" {
+			board = newLeaderboard()
+			go func() {
+				if err := http.ListenAndServe(*live, board); err != nil {
+					fmt.Fprintln(os.Stderr, "live leaderboard:", err)
+				}
+			}()
+		}
+		runLive(in, out, cfg, board)
+		return nil
+	}
 
-	run(in, out, cfg)
+	switch args[0] {
+	case "correct":
+		if len(args) != 2 {
+			return usageError("usage: goathlon correct <corrections file>")
+		}
+		if len(cfg.Competitions) > 0 {
+			return fmt.Errorf("corrections are not supported for several competitions")
+		}
+		corrections := must(loadCorrections(args[1]))
+		runCorrections(in, out, cfg, corrections)
+	case "startlist":
+		flags := flag.NewFlagSet("startlist", flag.ExitOnError)
+		seed := flags.Uint64("seed", 0, "seed of the random draw")
+		at := flags.String("at", "", "time the start list is announced at, half an hour before the start by default")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var atTime time.Time
+		if *at != "" {
+			var err error
+			if atTime, err = time.Parse(time.TimeOnly, *at); err != nil {
+				return usageError(fmt.Sprintf("invalid -at %q, the time is written as 15:04:05", *at))
+			}
+		}
+		return runStartList(in, out, cfg, *seed, atTime)
+	case "splits":
+		runSplits(in, out, cfg)
+	case "config":
+		if len(args) != 2 || args[1] != "print" {
+			return usageError("usage: goathlon config print")
+		}
+		if err := printConfig(out, cfg); err != nil {
+			panic(err)
+		}
+	case "schema":
+		if _, ok := schemas[args[len(args)-1]]; len(args) != 2 || !ok {
+			return usageError("usage: goathlon schema config|events")
+		}
+		if err := writeSchema(out, args[1]); err != nil {
+			panic(err)
+		}
+	case "season":
+		if len(args) != 2 {
+			return usageError("usage: goathlon season <season file>")
+		}
+		runSeason(out, must(loadSeason(args[1])))
+	default:
+		return usageError(fmt.Sprintf("unknown command: %s\nusage: goathlon [flags] [correct|startlist|splits|config|schema|season]", args[0]))
+	}
+	return nil
 }
@@ -2,8 +2,11 @@ package main
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,3 +52,221 @@ func TestRunMultiple(t *testing.T) {
 
 	assert.Equal(string(want), out.String())
 }
+
+func TestRunPursuit(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/pursuit/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/pursuit/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/pursuit/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunMass(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/mass/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/mass/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/mass/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunRelay(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/relay/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/relay/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/relay/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunCorrections(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/corrections/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/corrections/config.json")
+	assert.Nil(err)
+
+	corrections, err := loadCorrections("examples/corrections/corrections")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/corrections/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	runCorrections(events, &out, cfg, corrections)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunStartListExample(t *testing.T) {
+	assert := assert.New(t)
+
+	roster, err := os.Open("examples/startlist/entries")
+	assert.Nil(err)
+	defer roster.Close()
+
+	cfg, err := loadConfig("examples/startlist/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/startlist/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	assert.Nil(runStartList(roster, &out, cfg, 2026, time.Time{}))
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunCategories(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/categories/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/categories/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/categories/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunTeams(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/teams/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/teams/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/teams/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunCompetitions(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/competitions/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/competitions/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/competitions/output")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	run(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunSplits(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := os.Open("examples/multiple/events")
+	assert.Nil(err)
+	defer events.Close()
+
+	cfg, err := loadConfig("examples/multiple/config.json")
+	assert.Nil(err)
+
+	want, err := os.ReadFile("examples/multiple/splits")
+	assert.Nil(err)
+
+	var out bytes.Buffer
+	runSplits(events, &out, cfg)
+
+	assert.Equal(string(want), out.String())
+}
+
+func TestRunCommandUsage(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "examples/single/config.json")
+
+	for _, args := range [][]string{
+		{"correct"},
+		{"config"},
+		{"config", "show"},
+		{"schema"},
+		{"schema", "results"},
+		{"season"},
+		{"startlist", "-at", "noon"},
+		{"results"},
+	} {
+		var usage usageError
+		err := runCommand(args, strings.NewReader(""), io.Discard)
+		assert.ErrorAs(t, err, &usage, args)
+	}
+
+	// The usage is only reported for mistakes.
+	var out bytes.Buffer
+	assert.NoError(t, runCommand([]string{"schema", "events"}, strings.NewReader(""), &out))
+	assert.Contains(t, out.String(), "goathlon event line")
+}
+
+func TestRunCommandEnvOverrides(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "examples/single/config.json")
+	t.Setenv("GOATHLON_LAPS", "3")
+	t.Setenv("GOATHLON_LAP_LEN", "1000")
+
+	// The flags take precedence over the environment.
+	var out bytes.Buffer
+	assert.NoError(t, runCommand([]string{"--laps", "4", "config", "print"}, strings.NewReader(""), &out))
+	assert.Contains(t, out.String(), `"laps": 4,`)
+	assert.Contains(t, out.String(), `"lapLen": [
+    1000
+  ],`)
+
+	// A bad variable is only an error if there is a config to override.
+	t.Setenv("GOATHLON_LAPZ", "3")
+	assert.EqualError(t, runCommand([]string{"config", "print"}, strings.NewReader(""), io.Discard),
+		"GOATHLON_LAPZ doesn't override any config field")
+	assert.NoError(t, runCommand([]string{"schema", "config"}, strings.NewReader(""), io.Discard))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding config fields, e.g. GOATHLON_LAPS.
+const EnvPrefix = "GOATHLON_"
+
+// Overrides holds the values overriding config fields by the JSON name of the field.
+// The config file is overridden by the environment, which is overridden by the command line flags.
+type Overrides map[string]string
+
+// configFields returns the types of the config fields by their JSON names.
+func configFields() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if name := f.Tag.Get("json"); f.IsExported() && name != "" {
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+// envName returns the name of the environment variable overriding the field, e.g. GOATHLON_LAP_LEN for lapLen.
+func envName(field string) string {
+	var b strings.Builder
+	b.WriteString(EnvPrefix)
+	for i, r := range field {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// envOverrides collects the overrides from the environment given as "key=value" strings.
+// A variable with the prefix that doesn't name a config field is an error.
+func envOverrides(environ []string) (Overrides, error) {
+	fields := make(map[string]string)
+	for field := range configFields() {
+		fields[envName(field)] = field
+	}
+
+	overrides := make(Overrides)
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, EnvPrefix) {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("%s doesn't override any config field", key)
+		}
+		if err := overrides.set(field, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return overrides, nil
+}
+
+// registerFlags adds a flag for every config field to the flag set, e.g. --laps 3.
+// The values of the flags given are stored in the overrides.
+func (o Overrides) registerFlags(flags *flag.FlagSet) {
+	fields := configFields()
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		usage := fmt.Sprintf("override '%s' of the config, also set by %s", field, envName(field))
+		flags.Func(field, usage, func(value string) error {
+			return o.set(field, value)
+		})
+	}
+}
+
+// set checks the value of the field and stores it.
+func (o Overrides) set(field, value string) error {
+	t, ok := configFields()[field]
+	if !ok {
+		return fmt.Errorf("unknown config field '%s'", field)
+	}
+	raw, err := overrideValue(t, value)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for '%s'", value, field)
+	}
+	if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+		return fmt.Errorf("invalid value %q for '%s': %w", value, field, err)
+	}
+	o[field] = value
+	return nil
+}
+
+// overrideValue converts the value of an override to JSON. Strings don't need quotes and
+// lists may be written separated by commas, e.g. "prone,standing". Anything else is written as JSON.
+func overrideValue(t reflect.Type, value string) (json.RawMessage, error) {
+	switch {
+	case t.Kind() == reflect.String:
+		return json.Marshal(value)
+	case json.Valid([]byte(value)):
+		return json.RawMessage(value), nil
+	case t.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		for item := range strings.SplitSeq(value, ",") {
+			raw, err := overrideValue(t.Elem(), strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, raw)
+		}
+		return json.Marshal(items)
+	default:
+		return json.Marshal(value)
+	}
+}
+
+// apply writes the overrides into the JSON config. The overrides also replace the fields
+// set by the competitions of the config, except for the ID that tells them apart.
+func (o Overrides) apply(data []byte) ([]byte, error) {
+	if len(o) == 0 {
+		return data, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	if doc == nil {
+		doc = make(map[string]json.RawMessage)
+	}
+
+	fields := configFields()
+	values := make(map[string]json.RawMessage, len(o))
+	for field, value := range o {
+		raw, err := overrideValue(fields[field], value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for '%s'", value, field)
+		}
+		values[field] = raw
+		doc[field] = raw
+	}
+
+	if _, ok := o["competitions"]; !ok && doc["competitions"] != nil {
+		var competitions []map[string]json.RawMessage
+		if err := json.Unmarshal(doc["competitions"], &competitions); err != nil {
+			return nil, fmt.Errorf("parsing config: %w", err)
+		}
+		for _, c := range competitions {
+			for field, raw := range values {
+				if _, ok := c[field]; ok && field != "id" {
+					c[field] = raw
+				}
+			}
+		}
+		raw, err := json.Marshal(competitions)
+		if err != nil {
+			return nil, err
+		}
+		doc["competitions"] = raw
+	}
+
+	return json.Marshal(doc)
+}
+
+// printConfig writes the effective config as indented JSON.
+func printConfig(w io.Writer, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
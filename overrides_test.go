@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "GOATHLON_LAPS", envName("laps"))
+	assert.Equal(t, "GOATHLON_LAP_LEN", envName("lapLen"))
+	assert.Equal(t, "GOATHLON_TEAM_CLASSIFICATION", envName("teamClassification"))
+	assert.Equal(t, "GOATHLON_ID", envName("id"))
+}
+
+func TestEnvOverrides(t *testing.T) {
+	overrides, err := envOverrides([]string{
+		"HOME=/root",
+		"CONFIG_PATH=examples/single/config.json",
+		"GOATHLON_LAPS=3",
+		"GOATHLON_START_DELTA=1m30s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Overrides{"laps": "3", "startDelta": "1m30s"}, overrides)
+
+	_, err = envOverrides([]string{"GOATHLON_LAPZ=3"})
+	assert.EqualError(t, err, "GOATHLON_LAPZ doesn't override any config field")
+
+	_, err = envOverrides([]string{"GOATHLON_LAPS=three"})
+	assert.ErrorContains(t, err, `GOATHLON_LAPS: invalid value "three" for 'laps'`)
+}
+
+func TestOverridesFlags(t *testing.T) {
+	overrides := Overrides{"laps": "3", "penaltyLen": "100"}
+	flags := flag.NewFlagSet("goathlon", flag.ContinueOnError)
+	overrides.registerFlags(flags)
+
+	err := flags.Parse([]string{"--laps", "4", "-positions", "prone,standing", "correct", "file"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"correct", "file"}, flags.Args())
+	assert.Equal(t, Overrides{"laps": "4", "penaltyLen": "100", "positions": "prone,standing"}, overrides)
+
+	flags.SetOutput(&bytes.Buffer{})
+	assert.Error(t, flags.Parse([]string{"--startDelta", "soon"}))
+}
+
+func TestLoadConfigWith(t *testing.T) {
+	cfg, err := loadConfigWith("examples/single/config.json", Overrides{
+		"laps":        "3",
+		"lapLen":      "1000,1200,1000",
+		"firingLines": "2",
+		"positions":   "prone,standing",
+		"startDelta":  "45",
+		"start":       "11:00:00",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.Laps)
+	assert.Equal(t, LapLengths{1000, 1200, 1000}, cfg.LapLen)
+	assert.Equal(t, []string{"prone", "standing"}, cfg.Positions)
+	assert.Equal(t, 45*time.Second, cfg.StartDelta.Duration)
+	assert.Equal(t, "11:00:00", cfg.Start.Format(time.TimeOnly))
+	// Fields that aren't overridden come from the file.
+	assert.Equal(t, 50, cfg.PenaltyLen)
+
+	// The overridden config is validated as well.
+	_, err = loadConfigWith("examples/single/config.json", Overrides{"laps": "0"})
+	assert.ErrorContains(t, err, "'laps' must be positive")
+}
+
+func TestLoadConfigWithCompetitions(t *testing.T) {
+	cfg, err := loadConfigWith("examples/competitions/config.json", Overrides{"laps": "3", "lapLen": "2000", "id": "ignored"})
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Competitions, 2)
+	for _, c := range cfg.Competitions {
+		assert.Equal(t, 3, c.Laps, c.ID)
+		// The override wins over the lap length set by the competition itself.
+		assert.Equal(t, LapLengths{2000}, c.LapLen, c.ID)
+	}
+	assert.Equal(t, "women", cfg.Competitions[0].ID)
+	assert.Equal(t, "men", cfg.Competitions[1].ID)
+}
+
+func TestPrintConfig(t *testing.T) {
+	cfg, err := loadConfig("examples/single/config.json")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, printConfig(&out, cfg))
+	assert.Contains(t, out.String(), `"startDelta": "00:00:30.000"`)
+
+	var got Config
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, cfg.Laps, got.Laps)
+	assert.Equal(t, cfg.StartDelta, got.StartDelta)
+}
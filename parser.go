@@ -4,15 +4,24 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
 // parseEventLine parses a single line of input into an Event object.
-// The input line is expected to have the format: [timestamp] eventID competitorID [extra...]
+// The input line is expected to have the format: [timestamp] [@competition] eventID competitorID [extra...]
 func parseEventLine(line string) (Event, error) {
 	parts := strings.Fields(line)
+
+	// The competition is given explicitly right after the timestamp.
+	var competition string
+	if len(parts) > 1 && strings.HasPrefix(parts[1], "@") {
+		competition = parts[1][1:]
+		parts = slices.Delete(parts, 1, 2)
+	}
+
 	if len(parts) < 3 {
 		return Event{}, fmt.Errorf("invalid line: %s", line)
 	}
@@ -41,6 +50,7 @@ func parseEventLine(line string) (Event, error) {
 		ID:           id,
 		CompetitorID: cid,
 		Extra:        parts[3:],
+		Competition:  competition,
 	}, nil
 }
 
@@ -65,3 +75,37 @@ func parseEvents(r io.Reader, w io.Writer) chan Event {
 	}()
 	return eventCh
 }
+
+// parseClockDuration parses a duration written as a clock time, e.g. "00:01:30.500".
+// A leading sign is allowed for negative durations.
+func parseClockDuration(s string) (time.Duration, error) {
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	t, err := time.Parse(time.TimeOnly, s)
+	if err != nil {
+		return 0, err
+	}
+
+	d := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+	return sign * d, nil
+}
+
+// formatEventLine formats an event back into the input line format accepted by parseEventLine.
+func formatEventLine(e Event) string {
+	parts := []string{"[" + e.Timestamp.Format("15:04:05.000") + "]"}
+	if e.Competition != "" {
+		parts = append(parts, "@"+e.Competition)
+	}
+	parts = append(parts, strconv.Itoa(e.ID), strconv.Itoa(e.CompetitorID))
+	return strings.Join(append(parts, e.Extra...), " ")
+}
@@ -165,3 +165,49 @@ func TestParseScanner(t *testing.T) {
 type errorReader struct{ err error }
 
 func (r *errorReader) Read(p []byte) (int, error) { return 0, r.err }
+
+func TestParseClockDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "00:01:30", want: 90 * time.Second},
+		{input: "01:00:00.250", want: time.Hour + 250*time.Millisecond},
+		{input: "+00:00:10", want: 10 * time.Second},
+		{input: "-00:00:10.5", want: -10*time.Second - 500*time.Millisecond},
+		{input: "DNF", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseClockDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseEventLineCompetition(t *testing.T) {
+	evt, err := parseEventLine("[09:30:00] @women 1 123")
+	assert.NoError(t, err)
+	assert.Equal(t, "women", evt.Competition)
+	assert.Equal(t, EventRegistered, evt.ID)
+	assert.Equal(t, 123, evt.CompetitorID)
+
+	_, err = parseEventLine("[09:30:00] @women 1")
+	assert.Error(t, err)
+}
+
+func TestFormatEventLine(t *testing.T) {
+	for _, line := range []string{"[09:30:00.500] 5 456 1", "[10:00:00.000] 2 1 10:01:30.000", "[10:00:00.000] 4 7", "[10:00:00.000] @men 4 7"} {
+		evt, err := parseEventLine(line)
+		assert.NoError(t, err)
+		assert.Equal(t, line, formatEventLine(evt))
+	}
+}
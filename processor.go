@@ -1,8 +1,11 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,11 +16,33 @@ const (
 	StatusDisqualified                  // Whether the competitor has been disqualified.
 	StatusCantContinue                  // Whether the competitor cannot continue the race.
 	StatusFinished                      // Whether the competitor has finished the race.
+	StatusLapped                        // Whether the competitor has been lapped and pulled from the course.
+	StatusNotStarted                    // Whether the competitor has registered but never started.
+	StatusUnfinished                    // Whether the competitor has started but there is no record of the finish.
 
-	// Number of targets in the firing range.
+	// Number of targets in the firing range unless configured otherwise.
 	NumberOfTargets = 5
 )
 
+// String returns the official abbreviation of the status.
+// Competitors that are still active or have finished have no abbreviation.
+func (s CompetitorStatus) String() string {
+	switch s {
+	case StatusDisqualified:
+		return "DSQ"
+	case StatusCantContinue:
+		return "DNF"
+	case StatusLapped:
+		return "LAP"
+	case StatusNotStarted:
+		return "DNS"
+	case StatusUnfinished:
+		return "UNF"
+	default:
+		return ""
+	}
+}
+
 // Summary represents a mapping of competitor IDs to their states.
 type Summary = map[int]*CompetitorState
 
@@ -33,6 +58,16 @@ type Penalty struct {
 	Duration   time.Duration
 }
 
+// Bout is a single visit of the firing range.
+type Bout struct {
+	FiringRange int
+	Lane        int // The shooting lane, zero if it is not assigned.
+	Hits        int
+	Position    string
+	Spares      int // The number of spare rounds loaded by hand.
+	Shots       int // The number of shots fired, zero if shots are not reported.
+}
+
 type CompetitorState struct {
 	CompetitorID       int
 	ScheduledStartTime time.Time
@@ -43,38 +78,200 @@ type CompetitorState struct {
 	TotalPenaltyTime   time.Duration
 	TotalPenaltyLaps   int
 	TotalHits          int
+	TotalMisses        int
+	TotalSpares        int
+	TotalShots         int
 	CurrentHits        int
+	Bouts              []Bout
 	Status             CompetitorStatus
-	LastSeenTime       time.Time // The last time the competitor was seen.
+	StatusReason       string // Why the competitor is disqualified or did not finish.
+	TimePenalty        time.Duration
+	StatusBeforeJury   CompetitorStatus // The status to restore if the jury reinstates the competitor.
+	ReasonBeforeJury   string           // The reason to restore along with the status.
+	LastSeenTime       time.Time        // The last time the competitor was seen.
+	Checkpoints        []Checkpoint     // The checkpoints passed by the competitor in order.
+}
+
+// Checkpoint is a point of the course the intermediate times are taken at:
+// the end of every main lap and the exit of every firing range.
+type Checkpoint struct {
+	Name     string // e.g. "Shooting 1" or "Lap 2"
+	Time     time.Time
+	RaceTime time.Duration // The time of the competitor at the checkpoint as the final report counts it
+}
+
+// raceStatus returns the status of the competitor in the race. While disqualified,
+// it is the status he/she gets back if the jury reinstates him/her.
+func (st *CompetitorState) raceStatus() CompetitorStatus {
+	if st.Status == StatusDisqualified {
+		return st.StatusBeforeJury
+	}
+	return st.Status
+}
+
+// swapJuryStatus swaps the status of a disqualified competitor with the status to restore.
+func (st *CompetitorState) swapJuryStatus() {
+	st.Status, st.StatusBeforeJury = st.StatusBeforeJury, st.Status
+	st.StatusReason, st.ReasonBeforeJury = st.ReasonBeforeJury, st.StatusReason
+}
+
+// raceTime returns the time of the competitor at the given moment as the final report counts it:
+// from the scheduled start, with the penalty time for the targets missed so far and the time penalties of the jury.
+func (st *CompetitorState) raceTime(cfg Config, at time.Time) time.Duration {
+	return at.Sub(st.ScheduledStartTime) + time.Duration(st.TotalMisses)*cfg.PenaltyTime.Duration + st.TimePenalty
+}
+
+// completedLaps returns the number of main laps the competitor has ended.
+func (st *CompetitorState) completedLaps() int {
+	completed := 0
+	for _, lap := range st.Laps {
+		if !lap.FinishTime.IsZero() {
+			completed++
+		}
+	}
+	return completed
 }
 
 // processEvents logs events, updates competitor states, and generates summary data.
 func processEvents(w io.Writer, cfg Config, inCh chan Event) Summary {
-	summary := make(Summary)
+	return processEventsLive(w, cfg, inCh, nil)
+}
 
+// processEventsLive processes the events like processEvents and keeps the leaderboard up to date if there is one.
+func processEventsLive(w io.Writer, cfg Config, inCh chan Event, board *Leaderboard) Summary {
+	p := newProcessor(cfg)
+	p.board = board
 	for evt := range inCh {
-		logEvent(w, evt)
+		p.process(w, evt)
+	}
+	return p.finish()
+}
 
-		state := getOrCreateState(summary, evt.CompetitorID)
+// processor holds the state of a competition while its events are processed one by one.
+type processor struct {
+	cfg     Config
+	summary Summary
+	board   *Leaderboard // The live leaderboard the standings are published to, nil if there is none
+	last    time.Time    // The time of the last event processed
+}
 
-		// Skip processing if the competitor is disqualified, cannot continue, or has finished.
-		if shouldSkip(state) {
-			continue
+func newProcessor(cfg Config) *processor {
+	return &processor{cfg: cfg, summary: make(Summary)}
+}
+
+// process logs the event, updates the competitor's state and logs any outgoing event.
+func (p *processor) process(w io.Writer, evt Event) {
+	p.last = evt.Timestamp
+
+	logEvent(w, evt)
+
+	state := getOrCreateState(p.summary, evt.CompetitorID)
+
+	// The race of a disqualified competitor is still followed, so that it counts if the jury reinstates him/her.
+	// The event is applied to the status to restore, the competitor stays disqualified until then.
+	followed := state.Status == StatusDisqualified && !isJuryEvent(evt.ID)
+	if followed {
+		state.swapJuryStatus()
+	}
+	changed := p.apply(w, evt, state)
+	if followed {
+		state.swapJuryStatus()
+	}
+
+	if changed {
+		p.publish(evt.Timestamp, false)
+	}
+}
+
+// apply updates the competitor's state and logs any outgoing event.
+// It reports whether the standings have changed.
+func (p *processor) apply(w io.Writer, evt Event, state *CompetitorState) bool {
+	cfg, summary := p.cfg, p.summary
+
+	// Skip processing if the competitor is disqualified, cannot continue, or has finished.
+	// Jury decisions are applied regardless.
+	if shouldSkip(state) && !isJuryEvent(evt.ID) {
+		return false
+	}
+	prevStatus := state.Status
+
+	// The previous leg must have finished before the relay is taken over.
+	if evt.ID == EventHandover {
+		if err := checkHandover(cfg, summary, evt); err != nil {
+			logError(w, "update failed", err)
+			return false
 		}
+	}
 
-		// Update the competitor's state based on the event.
-		if err := updateState(cfg, evt, state); err != nil {
+	// A drawn start time must be on the start grid and not taken by another competitor.
+	if evt.ID == EventSetStartTime {
+		if err := checkStartDraw(cfg, summary, evt); err != nil {
 			logError(w, "update failed", err)
-			continue
+			return false
 		}
+	}
+
+	// Update the competitor's state based on the event.
+	if err := updateState(cfg, evt, state); err != nil {
+		logError(w, "update failed", err)
+		return false
+	}
 
-		// Generate and log any outgoing events based on the updated state.
+	// Pull the competitor from the course if he/she has been lapped.
+	pullIfLapped(cfg, summary, evt, state)
+
+	// Generate and log any outgoing events if the status has changed.
+	// A reinstated competitor gets his/her previous status back, which has been logged already.
+	if state.Status != prevStatus && evt.ID != EventJuryReinstated {
 		if outEvt, ok := maybeGenerateEvent(evt, state); ok {
 			logEvent(w, outEvt)
 		}
 	}
 
-	return summary
+	// The standings change at every checkpoint and whenever a competitor's status changes.
+	return evt.ID == EventFinishedLap || evt.ID == EventFinishedFiringRange || isJuryEvent(evt.ID) || state.Status != prevStatus
+}
+
+// finish classifies the competitors that are still active and returns the summary of the competition.
+func (p *processor) finish() Summary {
+	finishStates(p.cfg, p.summary)
+	p.publish(p.last, true)
+	return p.summary
+}
+
+// publish publishes the current standings to the live leaderboard if there is one.
+func (p *processor) publish(at time.Time, final bool) {
+	if p.board == nil {
+		return
+	}
+	p.board.publish(Snapshot{
+		Competition: p.cfg.ID,
+		Time:        Time{at},
+		Final:       final,
+		Standings:   liveStandings(p.cfg, p.summary),
+	})
+}
+
+// finishStates classifies the competitors that are still active once all events have been processed.
+// Competitors that have never started are marked as not started, the others as unfinished.
+// The reason explains what is missing.
+func finishStates(cfg Config, summary Summary) {
+	for _, st := range summary {
+		if st.Status != StatusActive {
+			continue
+		}
+		switch {
+		case !st.ActualStartTime.IsZero():
+			st.Status = StatusUnfinished
+			st.StatusReason = fmt.Sprintf("no finish recorded after %d of %d laps", st.completedLaps(), cfg.Laps)
+		case !st.ScheduledStartTime.IsZero():
+			st.Status = StatusNotStarted
+			st.StatusReason = "no start recorded"
+		default:
+			st.Status = StatusNotStarted
+			st.StatusReason = "no start time set"
+		}
+	}
 }
 
 // getOrCreateState retrieves the state for a competitor or creates a new one if it doesn't exist.
@@ -95,17 +292,29 @@ func shouldSkip(state *CompetitorState) bool {
 // updateState updates the state of a competitor based on an incoming event.
 func updateState(cfg Config, evt Event, st *CompetitorState) error {
 	switch evt.ID {
+	case EventRegistered:
+		return handleRegistered(cfg, evt, st)
+
 	case EventSetStartTime:
+		if cfg.Format == FormatMass {
+			return fmt.Errorf("start time is fixed to %s in a mass start", cfg.Start.Format("15:04:05.000"))
+		}
 		return handleSetStartTime(evt, st)
 
 	case EventStartedRace:
 		return handleStartedRace(cfg, evt, st)
 
+	case EventStartedFiringRange:
+		return handleStartedFiringRange(cfg, evt, st)
+
 	case EventShotHit:
 		return handleShotHit(st)
 
+	case EventFinishedFiringRange:
+		return handleFinishedFiringRange(cfg, evt, st)
+
 	case EventStartedPenaltyLaps:
-		return handleStartedPenaltyLaps(evt, st)
+		return handleStartedPenaltyLaps(cfg, evt, st)
 
 	case EventFinishedPenaltyLaps:
 		return handleFinishedPenaltyLaps(evt, st)
@@ -116,25 +325,73 @@ func updateState(cfg Config, evt Event, st *CompetitorState) error {
 	case EventCantContinue:
 		return handleCantContinue(evt, st)
 
+	case EventHandover:
+		return handleHandover(evt, st)
+
+	case EventSpareLoaded:
+		return handleSpareLoaded(cfg, st)
+
+	case EventShotFired:
+		return handleShotFired(st)
+
+	case EventJuryDisqualified:
+		return handleJuryDisqualified(evt, st)
+
+	case EventJuryTimePenalty:
+		return handleJuryTimePenalty(evt, st)
+
+	case EventJuryReinstated:
+		return handleJuryReinstated(evt, st)
+
 	default:
 		return nil
 	}
 }
 
+// handleRegistered sets the scheduled start time of a competitor if it is not drawn.
+// In a pursuit a start time set later by a draw takes precedence over the seeded one.
+// If there is a roster, only the competitors on the roster may register.
+func handleRegistered(cfg Config, evt Event, st *CompetitorState) error {
+	if _, ok := cfg.competitors[evt.CompetitorID]; cfg.competitors != nil && !ok {
+		return fmt.Errorf("competitor(%d) is not on the roster", evt.CompetitorID)
+	}
+
+	switch cfg.Format {
+	case FormatPursuit:
+		t, ok := cfg.startTimes[evt.CompetitorID]
+		if !ok {
+			return fmt.Errorf("competitor(%d) is not seeded for the pursuit", evt.CompetitorID)
+		}
+		st.ScheduledStartTime = t
+	case FormatMass:
+		st.ScheduledStartTime = cfg.Start.Time
+	case FormatRelay:
+		// Only the first leg starts at the configured start time, the others start on handover.
+		_, leg, ok := cfg.teamOf(evt.CompetitorID)
+		if !ok {
+			return fmt.Errorf("competitor(%d) is not a member of any relay team", evt.CompetitorID)
+		}
+		if leg == 0 {
+			st.ScheduledStartTime = cfg.Start.Time
+		}
+	}
+	return nil
+}
+
 // handleSetStartTime sets the scheduled start time for the competitor.
 func handleSetStartTime(evt Event, st *CompetitorState) error {
-	t, err := time.Parse(time.TimeOnly, evt.Extra[0])
+	t, err := parseStartTime(evt)
 	if err != nil {
-		return fmt.Errorf("invalid start time: %w", err)
+		return err
 	}
 	st.ScheduledStartTime = t
 	return nil
 }
 
 // handleStartedPenaltyLaps starts tracking the penalty laps for the competitor.
-func handleStartedPenaltyLaps(evt Event, st *CompetitorState) error {
+func handleStartedPenaltyLaps(cfg Config, evt Event, st *CompetitorState) error {
 	st.CurrentPenalty.StartTime = evt.Timestamp
-	st.TotalPenaltyLaps += NumberOfTargets - st.CurrentHits
+	st.TotalPenaltyLaps += cfg.targets() - st.CurrentHits
 	st.CurrentHits = 0
 	return nil
 }
@@ -153,12 +410,26 @@ func handleFinishedPenaltyLaps(evt Event, st *CompetitorState) error {
 
 // handleStartedRace sets the actual start time and initializes the first lap for the competitor.
 func handleStartedRace(cfg Config, evt Event, st *CompetitorState) error {
+	if cfg.Format == FormatRelay {
+		if _, leg, _ := cfg.teamOf(evt.CompetitorID); leg > 0 {
+			return fmt.Errorf("competitor(%d) runs leg %d and starts on handover", evt.CompetitorID, leg+1)
+		}
+	}
+
 	st.ActualStartTime = evt.Timestamp
 
-	// Check if the competitor started within the allowed interval.
-	deadline := st.ScheduledStartTime.Add(cfg.StartDelta.Duration)
-	if evt.Timestamp.Before(st.ScheduledStartTime) || evt.Timestamp.After(deadline) {
-		st.Status = StatusDisqualified
+	// Check if the competitor started within the allowed tolerance.
+	// There is no individual start interval in a mass start or a relay.
+	deadline := st.ScheduledStartTime.Add(cfg.startTolerance())
+	if cfg.hasStartWindow() {
+		switch {
+		case evt.Timestamp.Before(st.ScheduledStartTime):
+			st.Status = StatusDisqualified
+			st.StatusReason = "false start"
+		case evt.Timestamp.After(deadline):
+			st.Status = StatusDisqualified
+			st.StatusReason = "late start"
+		}
 	}
 
 	// Add the first lap.
@@ -170,17 +441,21 @@ func handleStartedRace(cfg Config, evt Event, st *CompetitorState) error {
 	return nil
 }
 
-// handleFinishedLap updates the finish time and duration of the current lap.
+// handleFinishedLap updates the finish time and duration of the current lap, ending a lap is a checkpoint.
 // If all laps are completed, it marks the race as finished.
 func handleFinishedLap(cfg Config, evt Event, st *CompetitorState) error {
 	st.Laps[len(st.Laps)-1].FinishTime = evt.Timestamp
 	st.Laps[len(st.Laps)-1].Duration += evt.Timestamp.Sub(st.Laps[len(st.Laps)-1].StartTime)
+	st.Checkpoints = append(st.Checkpoints, Checkpoint{
+		Name:     fmt.Sprintf("Lap %d", len(st.Laps)),
+		Time:     evt.Timestamp,
+		RaceTime: st.raceTime(cfg, evt.Timestamp),
+	})
 
 	if len(st.Laps) == cfg.Laps {
 		st.Status = StatusFinished
-		for lap := range st.Laps {
-			st.TotalRaceDuration += st.Laps[lap].Duration
-		}
+		// Every missed target may be penalized with extra time instead of a penalty lap.
+		st.TotalRaceDuration = st.raceTime(cfg, evt.Timestamp)
 	} else {
 		st.Laps = append(st.Laps, Lap{
 			StartTime: evt.Timestamp,
@@ -190,28 +465,164 @@ func handleFinishedLap(cfg Config, evt Event, st *CompetitorState) error {
 	return nil
 }
 
+// handleStartedFiringRange starts a new bout for the competitor.
+// In a mass start the lane for the first bout is fixed by the competitor's bib, the roster bib if there is one.
+// The lane may be given as a second extra parameter, in which case it is checked against the fixed one.
+func handleStartedFiringRange(cfg Config, evt Event, st *CompetitorState) error {
+	if len(evt.Extra) == 0 {
+		return fmt.Errorf("missing firing range")
+	}
+	firingRange, err := strconv.Atoi(evt.Extra[0])
+	if err != nil {
+		return fmt.Errorf("invalid firing range: %w", err)
+	}
+
+	bout := Bout{FiringRange: firingRange, Position: cfg.positionOf(len(st.Bouts))}
+	if cfg.Format == FormatMass && len(st.Bouts) == 0 {
+		bout.Lane = laneByBib(cfg, cmp.Or(cfg.competitor(evt.CompetitorID).Bib, evt.CompetitorID))
+	}
+
+	if len(evt.Extra) > 1 {
+		lane, err := strconv.Atoi(evt.Extra[1])
+		if err != nil {
+			return fmt.Errorf("invalid lane: %w", err)
+		}
+		if bout.Lane != 0 && lane != bout.Lane {
+			return fmt.Errorf("competitor(%d) shoots from lane %d instead of lane %d", evt.CompetitorID, lane, bout.Lane)
+		}
+		bout.Lane = lane
+	}
+
+	st.Bouts = append(st.Bouts, bout)
+	st.CurrentHits = 0
+	return nil
+}
+
+// handleFinishedFiringRange counts the targets missed during the current bout.
+// Leaving the firing range is a checkpoint.
+func handleFinishedFiringRange(cfg Config, evt Event, st *CompetitorState) error {
+	st.TotalMisses += max(cfg.targets()-st.CurrentHits, 0)
+	st.Checkpoints = append(st.Checkpoints, Checkpoint{
+		Name:     fmt.Sprintf("Shooting %d", len(st.Bouts)),
+		Time:     evt.Timestamp,
+		RaceTime: st.raceTime(cfg, evt.Timestamp),
+	})
+	return nil
+}
+
+// laneByBib returns the shooting lane fixed by the bib of the competitor.
+// Bibs wrap around if there are fewer lanes than competitors.
+func laneByBib(cfg Config, bib int) int {
+	if cfg.Lanes <= 0 {
+		return bib
+	}
+	return (bib-1)%cfg.Lanes + 1
+}
+
 // handleShotHit increments the hit counters for the competitor.
 func handleShotHit(st *CompetitorState) error {
 	st.CurrentHits++
 	st.TotalHits++
+	if len(st.Bouts) > 0 {
+		st.Bouts[len(st.Bouts)-1].Hits++
+	}
 	return nil
 }
 
 // handleCantContinue marks the competitor as unable to continue and updates the last seen time.
 func handleCantContinue(evt Event, st *CompetitorState) error {
 	st.Status = StatusCantContinue
+	st.StatusReason = strings.Join(evt.Extra, " ")
 	st.LastSeenTime = evt.Timestamp
 	return nil
 }
 
-// maybeGenerateEvent creates disqualification or race completion events if applicable.
+// handleHandover starts the competitor's leg at the time the relay was taken over.
+func handleHandover(evt Event, st *CompetitorState) error {
+	if !st.ActualStartTime.IsZero() {
+		return fmt.Errorf("competitor(%d) has already started", evt.CompetitorID)
+	}
+	st.ScheduledStartTime = evt.Timestamp
+	st.ActualStartTime = evt.Timestamp
+	st.Laps = append(st.Laps, Lap{StartTime: evt.Timestamp})
+	return nil
+}
+
+// handleSpareLoaded counts a spare round loaded by hand during the current bout.
+func handleSpareLoaded(cfg Config, st *CompetitorState) error {
+	if len(st.Bouts) == 0 {
+		return fmt.Errorf("competitor(%d) loaded a spare round outside the firing range", st.CompetitorID)
+	}
+	bout := &st.Bouts[len(st.Bouts)-1]
+	if bout.Spares >= cfg.SpareRounds {
+		return fmt.Errorf("competitor(%d) has no spare rounds left", st.CompetitorID)
+	}
+	bout.Spares++
+	st.TotalSpares++
+	return nil
+}
+
+// handleShotFired counts a shot fired during the current bout.
+func handleShotFired(st *CompetitorState) error {
+	if len(st.Bouts) == 0 {
+		return fmt.Errorf("competitor(%d) fired a shot outside the firing range", st.CompetitorID)
+	}
+	st.Bouts[len(st.Bouts)-1].Shots++
+	st.TotalShots++
+	return nil
+}
+
+// handleJuryDisqualified disqualifies the competitor with the reason code and text given by the jury.
+func handleJuryDisqualified(evt Event, st *CompetitorState) error {
+	if len(evt.Extra) == 0 {
+		return fmt.Errorf("missing disqualification reason")
+	}
+	if st.Status == StatusDisqualified {
+		return fmt.Errorf("competitor(%d) is already disqualified", evt.CompetitorID)
+	}
+	st.StatusBeforeJury, st.ReasonBeforeJury = st.Status, st.StatusReason
+	st.Status = StatusDisqualified
+	st.StatusReason = strings.Join(evt.Extra, " ")
+	return nil
+}
+
+// handleJuryTimePenalty adds the time penalty given by the jury to the competitor's time.
+// The penalty is added at the finish if the competitor has not finished yet.
+// A finished competitor disqualified by the jury keeps the penalty if he/she is reinstated.
+func handleJuryTimePenalty(evt Event, st *CompetitorState) error {
+	if len(evt.Extra) == 0 {
+		return fmt.Errorf("missing time penalty")
+	}
+	d, err := parseClockDuration(evt.Extra[0])
+	if err != nil {
+		return fmt.Errorf("invalid time penalty: %w", err)
+	}
+	st.TimePenalty += d
+	if st.raceStatus() == StatusFinished {
+		st.TotalRaceDuration += d
+	}
+	return nil
+}
+
+// handleJuryReinstated gives the competitor his/her status in the race back.
+// The race has been followed while disqualified, so a competitor who went on racing gets the finish.
+func handleJuryReinstated(evt Event, st *CompetitorState) error {
+	if st.Status != StatusDisqualified {
+		return fmt.Errorf("competitor(%d) is not disqualified", evt.CompetitorID)
+	}
+	st.swapJuryStatus()
+	st.StatusBeforeJury, st.ReasonBeforeJury = StatusActive, ""
+	return nil
+}
+
+// maybeGenerateEvent creates disqualification, race completion or lapped events if applicable.
 func maybeGenerateEvent(incoming Event, st *CompetitorState) (Event, bool) {
 	if st.Status == StatusDisqualified {
 		return Event{
 			Timestamp:    incoming.Timestamp,
 			ID:           EventDisqualified,
 			CompetitorID: incoming.CompetitorID,
-			Extra:        incoming.Extra,
+			Extra:        strings.Fields(st.StatusReason),
 		}, true
 	}
 	if st.Status == StatusFinished {
@@ -222,5 +633,12 @@ func maybeGenerateEvent(incoming Event, st *CompetitorState) (Event, bool) {
 			Extra:        incoming.Extra,
 		}, true
 	}
+	if st.Status == StatusLapped {
+		return Event{
+			Timestamp:    incoming.Timestamp,
+			ID:           EventLapped,
+			CompetitorID: incoming.CompetitorID,
+		}, true
+	}
 	return Event{}, false
 }
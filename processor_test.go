@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,7 +38,7 @@ func TestHandleStartedPenaltyLaps(t *testing.T) {
 	s := &CompetitorState{CurrentHits: 3}
 	evt := Event{Timestamp: time.Now()}
 
-	err := handleStartedPenaltyLaps(evt, s)
+	err := handleStartedPenaltyLaps(Config{}, evt, s)
 	assert.Nil(t, err)
 
 	assert.Equal(t, 2, s.TotalPenaltyLaps) // 5 targets - 3 hits
@@ -69,7 +71,7 @@ func TestProcessEvents(t *testing.T) {
 
 	cfg := Config{
 		Laps:        2,
-		LapLen:      3500,
+		LapLen:      LapLengths{3500},
 		PenaltyLen:  150,
 		FiringLines: 2,
 		Start:       Time{baseTime},
@@ -128,6 +130,7 @@ func TestProcessEvents(t *testing.T) {
 		assert.Len(t, summary, 1)
 		s := summary[1]
 		assert.Equal(t, StatusCantContinue, s.Status)
+		assert.Equal(t, "Took wrong turn", s.StatusReason)
 		assert.Equal(t, later, s.LastSeenTime)
 	})
 
@@ -151,7 +154,7 @@ func TestUpdateState(t *testing.T) {
 
 	cfg := Config{
 		Laps:        3,
-		LapLen:      3500,
+		LapLen:      LapLengths{3500},
 		PenaltyLen:  150,
 		FiringLines: 2,
 		Start:       Time{baseTime},
@@ -247,6 +250,7 @@ func TestHandleCantContinue(t *testing.T) {
 
 	assert.Equal(t, StatusCantContinue, st.Status)
 	assert.Equal(t, now, st.LastSeenTime)
+	assert.Empty(t, st.StatusReason)
 }
 
 func TestHandleCantContinueMultipleTimes(t *testing.T) {
@@ -313,3 +317,405 @@ func TestProcessEventsLogError(t *testing.T) {
 		assert.NotContains(t, logBuf.String(), "finished")
 	})
 }
+
+func TestHandleRegistered(t *testing.T) {
+	seeded := must(time.Parse(time.TimeOnly, "11:00:07"))
+	cfg := Config{Format: FormatPursuit, startTimes: map[int]time.Time{1: seeded}}
+
+	t.Run("seeded competitor", func(t *testing.T) {
+		st := &CompetitorState{}
+		err := handleRegistered(cfg, Event{ID: EventRegistered, CompetitorID: 1}, st)
+		require.NoError(t, err)
+		assert.Equal(t, seeded, st.ScheduledStartTime)
+	})
+
+	t.Run("not seeded competitor", func(t *testing.T) {
+		st := &CompetitorState{}
+		err := handleRegistered(cfg, Event{ID: EventRegistered, CompetitorID: 2}, st)
+		assert.Error(t, err)
+	})
+
+	t.Run("interval start", func(t *testing.T) {
+		st := &CompetitorState{}
+		err := handleRegistered(Config{}, Event{ID: EventRegistered, CompetitorID: 2}, st)
+		require.NoError(t, err)
+		assert.True(t, st.ScheduledStartTime.IsZero())
+	})
+
+	t.Run("roster", func(t *testing.T) {
+		cfg := Config{competitors: map[int]Competitor{1: {ID: 1}}}
+		assert.NoError(t, handleRegistered(cfg, Event{ID: EventRegistered, CompetitorID: 1}, &CompetitorState{}))
+		assert.ErrorContains(t, handleRegistered(cfg, Event{ID: EventRegistered, CompetitorID: 2}, &CompetitorState{}), "not on the roster")
+	})
+}
+
+func TestMassStart(t *testing.T) {
+	gun := must(time.Parse(time.TimeOnly, "12:00:00"))
+	cfg := Config{Laps: 1, Format: FormatMass, Start: Time{gun}, Lanes: 2}
+
+	t.Run("common start time", func(t *testing.T) {
+		st := &CompetitorState{}
+		require.NoError(t, updateState(cfg, Event{ID: EventRegistered, CompetitorID: 1}, st))
+		assert.Equal(t, gun, st.ScheduledStartTime)
+	})
+
+	t.Run("start time can't be drawn", func(t *testing.T) {
+		st := &CompetitorState{}
+		err := updateState(cfg, Event{ID: EventSetStartTime, Extra: []string{"12:00:00"}}, st)
+		assert.Error(t, err)
+	})
+
+	t.Run("no start window", func(t *testing.T) {
+		st := &CompetitorState{ScheduledStartTime: gun}
+		evt := Event{ID: EventStartedRace, Timestamp: gun.Add(time.Minute)}
+		require.NoError(t, updateState(cfg, evt, st))
+		assert.Equal(t, StatusActive, st.Status)
+	})
+}
+
+func TestHandleStartedFiringRange(t *testing.T) {
+	mass := Config{Format: FormatMass, Lanes: 2}
+	roster := Config{Format: FormatMass, Lanes: 10, competitors: map[int]Competitor{3: {ID: 3, Bib: 8}}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		bouts    []Bout
+		extra    []string
+		wantLane int
+		wantErr  bool
+	}{
+		{name: "lane by bib", cfg: mass, extra: []string{"1"}, wantLane: 1},
+		{name: "lane by roster bib", cfg: roster, extra: []string{"1"}, wantLane: 8},
+		{name: "matching lane", cfg: mass, extra: []string{"1", "1"}, wantLane: 1},
+		{name: "wrong lane", cfg: mass, extra: []string{"1", "2"}, wantErr: true},
+		{name: "free lane after first bout", cfg: mass, bouts: []Bout{{FiringRange: 1, Lane: 1}}, extra: []string{"2", "2"}, wantLane: 2},
+		{name: "no lanes in interval start", cfg: Config{}, extra: []string{"1"}, wantLane: 0},
+		{name: "missing firing range", cfg: mass, wantErr: true},
+		{name: "invalid firing range", cfg: mass, extra: []string{"first"}, wantErr: true},
+		{name: "invalid lane", cfg: Config{}, extra: []string{"1", "left"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &CompetitorState{Bouts: tt.bouts}
+			err := handleStartedFiringRange(tt.cfg, Event{CompetitorID: 3, Extra: tt.extra}, st)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLane, st.Bouts[len(st.Bouts)-1].Lane)
+		})
+	}
+}
+
+func TestHandleShotFired(t *testing.T) {
+	st := &CompetitorState{}
+	assert.Error(t, handleShotFired(st))
+
+	st.Bouts = []Bout{{FiringRange: 1}}
+	require.NoError(t, handleShotFired(st))
+	require.NoError(t, handleShotFired(st))
+	assert.Equal(t, 2, st.Bouts[0].Shots)
+	assert.Equal(t, 2, st.TotalShots)
+}
+
+func TestCurrentHitsPerBout(t *testing.T) {
+	cfg := Config{Targets: 5}
+	st := &CompetitorState{}
+
+	events := []Event{
+		{ID: EventStartedFiringRange, Extra: []string{"1"}},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventFinishedFiringRange},
+		{ID: EventStartedFiringRange, Extra: []string{"2"}},
+		{ID: EventShotHit},
+		{ID: EventFinishedFiringRange},
+		{ID: EventStartedPenaltyLaps},
+	}
+	for _, evt := range events {
+		require.NoError(t, updateState(cfg, evt, st))
+	}
+
+	assert.Equal(t, 4, st.TotalPenaltyLaps)
+	assert.Equal(t, 4, st.TotalMisses)
+	assert.Equal(t, 6, st.TotalHits)
+}
+
+func TestCheckpoints(t *testing.T) {
+	cfg := Config{Laps: 2, Targets: 5, PenaltyTime: Duration{time.Minute}}
+	at := func(s string) time.Time { return parseTime(t, time.TimeOnly, s) }
+	st := &CompetitorState{ScheduledStartTime: at("10:00:00")}
+
+	events := []Event{
+		{ID: EventStartedRace, Timestamp: at("10:00:10")},
+		{ID: EventStartedFiringRange, Timestamp: at("10:05:00"), Extra: []string{"1"}},
+		{ID: EventFinishedFiringRange, Timestamp: at("10:05:30")},
+		{ID: EventFinishedLap, Timestamp: at("10:08:00")},
+		{ID: EventFinishedLap, Timestamp: at("10:16:00")},
+	}
+	for _, evt := range events {
+		require.NoError(t, updateState(cfg, evt, st))
+	}
+
+	// The race time counts from the scheduled start and includes the penalty time for the 5 missed targets.
+	assert.Equal(t, []Checkpoint{
+		{Name: "Shooting 1", Time: at("10:05:30"), RaceTime: 10*time.Minute + 30*time.Second},
+		{Name: "Lap 1", Time: at("10:08:00"), RaceTime: 13 * time.Minute},
+		{Name: "Lap 2", Time: at("10:16:00"), RaceTime: 21 * time.Minute},
+	}, st.Checkpoints)
+	assert.Equal(t, 21*time.Minute, st.TotalRaceDuration)
+}
+
+func TestCompetitorStatusString(t *testing.T) {
+	assert.Equal(t, "", StatusActive.String())
+	assert.Equal(t, "DSQ", StatusDisqualified.String())
+	assert.Equal(t, "DNF", StatusCantContinue.String())
+	assert.Equal(t, "", StatusFinished.String())
+	assert.Equal(t, "LAP", StatusLapped.String())
+	assert.Equal(t, "DNS", StatusNotStarted.String())
+}
+
+func TestStartWindowReasons(t *testing.T) {
+	scheduled := must(time.Parse(time.TimeOnly, "09:00:00"))
+	cfg := Config{StartDelta: Duration{30 * time.Second}}
+
+	tests := []struct {
+		name       string
+		start      time.Time
+		wantStatus CompetitorStatus
+		wantReason string
+	}{
+		{name: "in time", start: scheduled.Add(10 * time.Second), wantStatus: StatusActive},
+		{name: "false start", start: scheduled.Add(-time.Second), wantStatus: StatusDisqualified, wantReason: "false start"},
+		{name: "late start", start: scheduled.Add(time.Minute), wantStatus: StatusDisqualified, wantReason: "late start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &CompetitorState{ScheduledStartTime: scheduled}
+			require.NoError(t, handleStartedRace(cfg, Event{ID: EventStartedRace, Timestamp: tt.start}, st))
+			assert.Equal(t, tt.wantStatus, st.Status)
+			assert.Equal(t, tt.wantReason, st.StatusReason)
+		})
+	}
+}
+
+func TestProcessEventsNotStarted(t *testing.T) {
+	inCh := make(chan Event, 2)
+	inCh <- Event{ID: EventRegistered, CompetitorID: 1}
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 1, Extra: []string{"09:00:00"}}
+	close(inCh)
+
+	var buf bytes.Buffer
+	summary := processEvents(&buf, Config{Laps: 1}, inCh)
+	assert.Equal(t, StatusNotStarted, summary[1].Status)
+}
+
+func TestFinishStates(t *testing.T) {
+	ts := time.Now()
+	summary := Summary{
+		1: {CompetitorID: 1},
+		2: {CompetitorID: 2, ScheduledStartTime: ts},
+		3: {CompetitorID: 3, ScheduledStartTime: ts, ActualStartTime: ts, Laps: []Lap{{FinishTime: ts}, {}}},
+		4: {CompetitorID: 4, Status: StatusFinished},
+	}
+
+	finishStates(Config{Laps: 3}, summary)
+
+	assert.Equal(t, StatusNotStarted, summary[1].Status)
+	assert.Equal(t, "no start time set", summary[1].StatusReason)
+	assert.Equal(t, StatusNotStarted, summary[2].Status)
+	assert.Equal(t, "no start recorded", summary[2].StatusReason)
+	assert.Equal(t, StatusUnfinished, summary[3].Status)
+	assert.Equal(t, "no finish recorded after 1 of 3 laps", summary[3].StatusReason)
+	assert.Equal(t, StatusFinished, summary[4].Status)
+	assert.Empty(t, summary[4].StatusReason)
+}
+
+func TestJuryDecisions(t *testing.T) {
+	t.Run("disqualify and reinstate", func(t *testing.T) {
+		st := &CompetitorState{Status: StatusFinished}
+
+		require.NoError(t, handleJuryDisqualified(Event{Extra: []string{"R7", "missed", "penalty", "loop"}}, st))
+		assert.Equal(t, StatusDisqualified, st.Status)
+		assert.Equal(t, "R7 missed penalty loop", st.StatusReason)
+		assert.Error(t, handleJuryDisqualified(Event{Extra: []string{"R1"}}, st))
+
+		require.NoError(t, handleJuryReinstated(Event{}, st))
+		assert.Equal(t, StatusFinished, st.Status)
+		assert.Empty(t, st.StatusReason)
+		assert.Error(t, handleJuryReinstated(Event{}, st))
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		assert.Error(t, handleJuryDisqualified(Event{}, &CompetitorState{}))
+	})
+
+	t.Run("time penalty after finish", func(t *testing.T) {
+		st := &CompetitorState{Status: StatusFinished, TotalRaceDuration: 20 * time.Minute}
+		require.NoError(t, handleJuryTimePenalty(Event{Extra: []string{"00:01:00"}}, st))
+		require.NoError(t, handleJuryTimePenalty(Event{Extra: []string{"-00:00:10"}}, st))
+		assert.Equal(t, 50*time.Second, st.TimePenalty)
+		assert.Equal(t, 20*time.Minute+50*time.Second, st.TotalRaceDuration)
+	})
+
+	t.Run("time penalty while disqualified after finish", func(t *testing.T) {
+		st := &CompetitorState{Status: StatusFinished, TotalRaceDuration: 10 * time.Minute}
+		cfg := Config{Laps: 1}
+
+		require.NoError(t, updateState(cfg, Event{ID: EventJuryDisqualified, Extra: []string{"R7"}}, st))
+		require.NoError(t, updateState(cfg, Event{ID: EventJuryTimePenalty, Extra: []string{"00:01:00"}}, st))
+		require.NoError(t, updateState(cfg, Event{ID: EventJuryReinstated}, st))
+		assert.Equal(t, StatusFinished, st.Status)
+		assert.Equal(t, time.Minute, st.TimePenalty)
+		assert.Equal(t, 11*time.Minute, st.TotalRaceDuration)
+	})
+
+	t.Run("time penalty before finish", func(t *testing.T) {
+		start := must(time.Parse(time.TimeOnly, "09:00:00"))
+		st := &CompetitorState{ScheduledStartTime: start}
+		cfg := Config{Laps: 1, StartDelta: Duration{30 * time.Second}}
+
+		require.NoError(t, updateState(cfg, Event{ID: EventStartedRace, Timestamp: start}, st))
+		require.NoError(t, updateState(cfg, Event{ID: EventJuryTimePenalty, Extra: []string{"00:00:30"}}, st))
+		require.NoError(t, updateState(cfg, Event{ID: EventFinishedLap, Timestamp: start.Add(10 * time.Minute)}, st))
+		assert.Equal(t, 10*time.Minute+30*time.Second, st.TotalRaceDuration)
+	})
+
+	t.Run("invalid time penalty", func(t *testing.T) {
+		assert.Error(t, handleJuryTimePenalty(Event{}, &CompetitorState{}))
+		assert.Error(t, handleJuryTimePenalty(Event{Extra: []string{"a", "minute"}}, &CompetitorState{}))
+	})
+}
+
+func TestProcessEventsJury(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "09:00:00"))
+	cfg := Config{Laps: 1, StartDelta: Duration{30 * time.Second}}
+
+	inCh := make(chan Event, 7)
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 1, Timestamp: start, Extra: []string{"09:00:00"}}
+	inCh <- Event{ID: EventStartedRace, CompetitorID: 1, Timestamp: start}
+	inCh <- Event{ID: EventFinishedLap, CompetitorID: 1, Timestamp: start.Add(10 * time.Minute)}
+	inCh <- Event{ID: EventJuryTimePenalty, CompetitorID: 1, Timestamp: start.Add(time.Hour), Extra: []string{"00:01:00"}}
+	inCh <- Event{ID: EventJuryDisqualified, CompetitorID: 1, Timestamp: start.Add(time.Hour), Extra: []string{"R1", "wrong", "rifle"}}
+	inCh <- Event{ID: EventJuryReinstated, CompetitorID: 1, Timestamp: start.Add(2 * time.Hour), Extra: []string{"protest"}}
+	inCh <- Event{ID: EventFinishedLap, CompetitorID: 1, Timestamp: start.Add(2 * time.Hour)}
+	close(inCh)
+
+	var buf bytes.Buffer
+	summary := processEvents(&buf, cfg, inCh)
+
+	assert.Equal(t, StatusFinished, summary[1].Status)
+	assert.Equal(t, 11*time.Minute, summary[1].TotalRaceDuration)
+	assert.Equal(t, 1, strings.Count(buf.String(), "is disqualified: R1 wrong rifle"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "has finished"))
+}
+
+func TestProcessEventsJuryReinstatedRace(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "09:00:00"))
+	cfg := Config{Laps: 2, StartDelta: Duration{30 * time.Second}}
+	process := func(events ...Event) *CompetitorState {
+		inCh := make(chan Event, len(events)+1)
+		inCh <- Event{ID: EventSetStartTime, CompetitorID: 1, Timestamp: start, Extra: []string{"09:00:00"}}
+		for _, evt := range events {
+			evt.CompetitorID = 1
+			inCh <- evt
+		}
+		close(inCh)
+		return processEvents(io.Discard, cfg, inCh)[1]
+	}
+
+	t.Run("disqualified during the race", func(t *testing.T) {
+		events := []Event{
+			{ID: EventStartedRace, Timestamp: start},
+			{ID: EventJuryDisqualified, Timestamp: start.Add(time.Minute), Extra: []string{"R7"}},
+			{ID: EventFinishedLap, Timestamp: start.Add(5 * time.Minute)},
+			{ID: EventFinishedLap, Timestamp: start.Add(10 * time.Minute)},
+		}
+
+		// The race goes on while disqualified, but only counts once reinstated.
+		st := process(events...)
+		assert.Equal(t, StatusDisqualified, st.Status)
+		assert.Equal(t, "R7", st.StatusReason)
+
+		st = process(append(events, Event{ID: EventJuryReinstated, Timestamp: start.Add(time.Hour)})...)
+		assert.Equal(t, StatusFinished, st.Status)
+		assert.Empty(t, st.StatusReason)
+		assert.Equal(t, 10*time.Minute, st.TotalRaceDuration)
+	})
+
+	t.Run("reinstated during the race", func(t *testing.T) {
+		st := process(
+			Event{ID: EventStartedRace, Timestamp: start},
+			Event{ID: EventJuryDisqualified, Timestamp: start.Add(time.Minute), Extra: []string{"R7"}},
+			Event{ID: EventFinishedLap, Timestamp: start.Add(5 * time.Minute)},
+			Event{ID: EventJuryReinstated, Timestamp: start.Add(6 * time.Minute)},
+			Event{ID: EventFinishedLap, Timestamp: start.Add(10 * time.Minute)},
+		)
+		assert.Equal(t, StatusFinished, st.Status)
+		assert.Equal(t, 10*time.Minute, st.TotalRaceDuration)
+	})
+
+	t.Run("disqualified for a late start", func(t *testing.T) {
+		st := process(
+			Event{ID: EventStartedRace, Timestamp: start.Add(time.Minute)},
+			Event{ID: EventFinishedLap, Timestamp: start.Add(6 * time.Minute)},
+			Event{ID: EventFinishedLap, Timestamp: start.Add(11 * time.Minute)},
+			Event{ID: EventJuryReinstated, Timestamp: start.Add(time.Hour)},
+		)
+		assert.Equal(t, StatusFinished, st.Status)
+		assert.Equal(t, 11*time.Minute, st.TotalRaceDuration)
+	})
+
+	t.Run("late start after a disqualification", func(t *testing.T) {
+		// The competitor gets the status of the race back, which is the late start.
+		st := process(
+			Event{ID: EventJuryDisqualified, Timestamp: start.Add(-time.Minute), Extra: []string{"R1"}},
+			Event{ID: EventStartedRace, Timestamp: start.Add(time.Minute)},
+			Event{ID: EventJuryReinstated, Timestamp: start.Add(time.Hour)},
+		)
+		assert.Equal(t, StatusDisqualified, st.Status)
+		assert.Equal(t, "late start", st.StatusReason)
+	})
+}
+
+func TestStartTolerance(t *testing.T) {
+	scheduled := must(time.Parse(time.TimeOnly, "09:00:00"))
+	cfg := Config{StartDelta: Duration{30 * time.Second}, StartTolerance: &Duration{time.Minute}}
+	assert.Equal(t, time.Minute, cfg.startTolerance())
+	assert.Equal(t, 30*time.Second, Config{StartDelta: Duration{30 * time.Second}}.startTolerance())
+
+	st := &CompetitorState{ScheduledStartTime: scheduled}
+	require.NoError(t, handleStartedRace(cfg, Event{ID: EventStartedRace, Timestamp: scheduled.Add(45 * time.Second)}, st))
+	assert.Equal(t, StatusActive, st.Status)
+
+	// A zero tolerance doesn't fall back to the start interval.
+	strict := Config{StartDelta: Duration{30 * time.Second}, StartTolerance: &Duration{}}
+	assert.Equal(t, time.Duration(0), strict.startTolerance())
+
+	st = &CompetitorState{ScheduledStartTime: scheduled}
+	require.NoError(t, handleStartedRace(strict, Event{ID: EventStartedRace, Timestamp: scheduled}, st))
+	assert.Equal(t, StatusActive, st.Status)
+
+	st = &CompetitorState{ScheduledStartTime: scheduled}
+	require.NoError(t, handleStartedRace(strict, Event{ID: EventStartedRace, Timestamp: scheduled.Add(time.Millisecond)}, st))
+	assert.Equal(t, "late start", st.StatusReason)
+}
+
+func TestProcessEventsDuplicateStartTime(t *testing.T) {
+	inCh := make(chan Event, 2)
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 1, Extra: []string{"09:00:00"}}
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 2, Extra: []string{"09:00:00"}}
+	close(inCh)
+
+	var buf bytes.Buffer
+	summary := processEvents(&buf, Config{Laps: 1}, inCh)
+	assert.Contains(t, buf.String(), "already been drawn for competitor(1)")
+	assert.True(t, summary[2].ScheduledStartTime.IsZero())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shooting positions.
+const (
+	PositionProne    = "prone"
+	PositionStanding = "standing"
+)
+
+// Profile describes a race format. Fields missing from the config are filled in from the selected profile.
+type Profile struct {
+	Format      string     `json:"format"`
+	Laps        int        `json:"laps"`
+	LapLen      LapLengths `json:"lapLen"`
+	PenaltyLen  int        `json:"penaltyLen"`
+	PenaltyTime Duration   `json:"penaltyTime"`
+	FiringLines int        `json:"firingLines"`
+	Positions   []string   `json:"positions"`
+	Targets     int        `json:"targets"`
+	SpareRounds int        `json:"spareRounds"`
+	StartDelta  Duration   `json:"startDelta"`
+}
+
+// builtinProfiles holds the standard race formats.
+var builtinProfiles = map[string]Profile{
+	"sprint": {
+		Format:      FormatInterval,
+		Laps:        3,
+		LapLen:      LapLengths{3333},
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Positions:   []string{PositionProne, PositionStanding},
+		Targets:     NumberOfTargets,
+		StartDelta:  Duration{30 * time.Second},
+	},
+	"individual": {
+		Format:      FormatInterval,
+		Laps:        5,
+		LapLen:      LapLengths{4000},
+		PenaltyTime: Duration{time.Minute},
+		FiringLines: 4,
+		Positions:   []string{PositionProne, PositionStanding, PositionProne, PositionStanding},
+		Targets:     NumberOfTargets,
+		StartDelta:  Duration{30 * time.Second},
+	},
+	"pursuit": {
+		Format:      FormatPursuit,
+		Laps:        5,
+		LapLen:      LapLengths{2500},
+		PenaltyLen:  150,
+		FiringLines: 4,
+		Positions:   []string{PositionProne, PositionProne, PositionStanding, PositionStanding},
+		Targets:     NumberOfTargets,
+		StartDelta:  Duration{5 * time.Second},
+	},
+	"massStart": {
+		Format:      FormatMass,
+		Laps:        5,
+		LapLen:      LapLengths{3000},
+		PenaltyLen:  150,
+		FiringLines: 4,
+		Positions:   []string{PositionProne, PositionProne, PositionStanding, PositionStanding},
+		Targets:     NumberOfTargets,
+	},
+	"superSprint": {
+		Format:      FormatMass,
+		Laps:        5,
+		LapLen:      LapLengths{1500},
+		PenaltyLen:  75,
+		FiringLines: 4,
+		Positions:   []string{PositionProne, PositionProne, PositionStanding, PositionStanding},
+		Targets:     NumberOfTargets,
+		SpareRounds: 3,
+	},
+	"relay": {
+		Format:      FormatRelay,
+		Laps:        3,
+		LapLen:      LapLengths{2500},
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Positions:   []string{PositionProne, PositionStanding},
+		Targets:     NumberOfTargets,
+		SpareRounds: 3,
+	},
+}
+
+// applyProfile fills in the fields left empty in the config from the selected profile.
+// A field given in the config file is kept even if it is zero, e.g. "spareRounds": 0.
+// Profiles defined in the config take precedence over the built-in ones with the same name.
+func (cfg *Config) applyProfile() error {
+	if cfg.Profile == "" {
+		return nil
+	}
+
+	p, ok := cfg.Profiles[cfg.Profile]
+	if !ok {
+		p, ok = builtinProfiles[cfg.Profile]
+	}
+	if !ok {
+		return fmt.Errorf("unknown profile: %s", cfg.Profile)
+	}
+
+	if cfg.Format == "" && !cfg.given["format"] {
+		cfg.Format = p.Format
+	}
+	if cfg.Laps == 0 && !cfg.given["laps"] {
+		cfg.Laps = p.Laps
+	}
+	if len(cfg.LapLen) == 0 && !cfg.given["lapLen"] {
+		cfg.LapLen = p.LapLen
+	}
+	if cfg.PenaltyLen == 0 && !cfg.given["penaltyLen"] {
+		cfg.PenaltyLen = p.PenaltyLen
+	}
+	if cfg.PenaltyTime.Duration == 0 && !cfg.given["penaltyTime"] {
+		cfg.PenaltyTime = p.PenaltyTime
+	}
+	if cfg.FiringLines == 0 && !cfg.given["firingLines"] {
+		cfg.FiringLines = p.FiringLines
+	}
+	if cfg.Positions == nil && !cfg.given["positions"] {
+		cfg.Positions = p.Positions
+	}
+	if cfg.Targets == 0 && !cfg.given["targets"] {
+		cfg.Targets = p.Targets
+	}
+	if cfg.SpareRounds == 0 && !cfg.given["spareRounds"] {
+		cfg.SpareRounds = p.SpareRounds
+	}
+	if cfg.StartDelta.Duration == 0 && !cfg.given["startDelta"] {
+		cfg.StartDelta = p.StartDelta
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	t.Run("no profile", func(t *testing.T) {
+		cfg := Config{Laps: 2}
+		require.NoError(t, cfg.applyProfile())
+		assert.Equal(t, Config{Laps: 2}, cfg)
+	})
+
+	t.Run("builtin profile", func(t *testing.T) {
+		cfg := Config{Profile: "individual", Laps: 4}
+		require.NoError(t, cfg.applyProfile())
+		assert.Equal(t, FormatInterval, cfg.Format)
+		assert.Equal(t, 4, cfg.Laps)
+		assert.Equal(t, LapLengths{4000}, cfg.LapLen)
+		assert.Equal(t, 0, cfg.PenaltyLen)
+		assert.Equal(t, time.Minute, cfg.PenaltyTime.Duration)
+		assert.Equal(t, 4, cfg.FiringLines)
+		assert.Equal(t, []string{PositionProne, PositionStanding, PositionProne, PositionStanding}, cfg.Positions)
+		assert.Equal(t, 5, cfg.Targets)
+		assert.Equal(t, 30*time.Second, cfg.StartDelta.Duration)
+	})
+
+	t.Run("start format from profile", func(t *testing.T) {
+		cfg := Config{Profile: "relay"}
+		require.NoError(t, cfg.applyProfile())
+		assert.Equal(t, FormatRelay, cfg.Format)
+		assert.Equal(t, 3, cfg.SpareRounds)
+	})
+
+	t.Run("custom profile", func(t *testing.T) {
+		cfg := Config{
+			Profile:  "sprint",
+			Profiles: map[string]Profile{"sprint": {Laps: 3, LapLen: LapLengths{2500}, Targets: 3}},
+		}
+		require.NoError(t, cfg.applyProfile())
+		assert.Equal(t, LapLengths{2500}, cfg.LapLen)
+		assert.Equal(t, 3, cfg.Targets)
+		assert.Equal(t, 0, cfg.FiringLines)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		cfg := Config{Profile: "marathon"}
+		assert.Error(t, cfg.applyProfile())
+	})
+}
+
+func TestBuiltinProfiles(t *testing.T) {
+	for name, p := range builtinProfiles {
+		t.Run(name, func(t *testing.T) {
+			assert.Positive(t, p.Laps)
+			assert.Positive(t, p.LapLen.Of(0))
+			assert.Equal(t, p.FiringLines, len(p.Positions))
+			assert.True(t, p.PenaltyLen > 0 || p.PenaltyTime.Duration > 0)
+		})
+	}
+}
+
+func TestPenaltyTime(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "10:00:00"))
+	cfg := Config{Laps: 1, Targets: 5, PenaltyTime: Duration{time.Minute}}
+	st := &CompetitorState{ScheduledStartTime: start}
+
+	events := []Event{
+		{ID: EventStartedRace, Timestamp: start},
+		{ID: EventStartedFiringRange, Extra: []string{"1"}},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventShotHit},
+		{ID: EventFinishedFiringRange},
+		{ID: EventFinishedLap, Timestamp: start.Add(10 * time.Minute)},
+	}
+	for _, evt := range events {
+		require.NoError(t, updateState(cfg, evt, st))
+	}
+
+	assert.Equal(t, 2, st.TotalMisses)
+	assert.Equal(t, 0, st.TotalPenaltyLaps)
+	assert.Equal(t, 12*time.Minute, st.TotalRaceDuration)
+}
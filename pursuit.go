@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// loadPursuitSeeds reads the results of a previous competition and computes pursuit start times.
+// The winner starts at the given start time, everybody else starts with the gap behind the winner.
+// Competitors that did not finish the previous competition are not seeded.
+func loadPursuitSeeds(path string, start time.Time) (map[int]time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries, err := readResults(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var finished []ResultEntry
+	for _, entry := range entries {
+		if entry.Finished {
+			finished = append(finished, entry)
+		}
+	}
+	if len(finished) == 0 {
+		return nil, fmt.Errorf("no finished competitors in %s", path)
+	}
+
+	best := finished[0].Time
+	for _, entry := range finished {
+		best = min(best, entry.Time)
+	}
+
+	startTimes := make(map[int]time.Time, len(finished))
+	for _, entry := range finished {
+		startTimes[entry.CompetitorID] = start.Add(entry.Time - best)
+	}
+
+	return startTimes, nil
+}
+
+// isLapped reports whether any other competitor has completed more laps than the given one.
+// It is meant to be checked right after the competitor has ended a lap.
+func isLapped(summary Summary, st *CompetitorState) bool {
+	laps := st.completedLaps()
+	for _, other := range summary {
+		if other != st && other.completedLaps() > laps {
+			return true
+		}
+	}
+	return false
+}
+
+// pullIfLapped pulls a lapped competitor from the course if the configuration allows it.
+func pullIfLapped(cfg Config, summary Summary, evt Event, st *CompetitorState) {
+	if cfg.Format != FormatPursuit || !cfg.PullLapped {
+		return
+	}
+	if evt.ID != EventFinishedLap || st.Status != StatusActive {
+		return
+	}
+	if isLapped(summary, st) {
+		st.Status = StatusLapped
+		st.LastSeenTime = evt.Timestamp
+	}
+}
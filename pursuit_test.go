@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPursuitSeeds(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "11:00:00"))
+
+	seeds, err := loadPursuitSeeds("examples/multiple/output", start)
+	require.NoError(t, err)
+
+	assert.Len(t, seeds, 5)
+	assert.Equal(t, start, seeds[2])
+	assert.Equal(t, start.Add(7691*time.Millisecond), seeds[1])
+	assert.Equal(t, start.Add(64116*time.Millisecond), seeds[5])
+}
+
+func TestLoadPursuitSeedsNoFinishers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	require.NoError(t, os.WriteFile(path, []byte("[NotFinished] 1 [{,}] {,} 0/5\n"), 0o600))
+
+	_, err := loadPursuitSeeds(path, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestLoadPursuitSeedsFileNotFound(t *testing.T) {
+	_, err := loadPursuitSeeds("non_existent_file", time.Time{})
+	assert.Error(t, err)
+}
+
+func TestIsLapped(t *testing.T) {
+	ts := time.Now()
+	leader := &CompetitorState{CompetitorID: 1, Laps: []Lap{{FinishTime: ts}, {FinishTime: ts}}}
+	chaser := &CompetitorState{CompetitorID: 2, Laps: []Lap{{FinishTime: ts}, {}}}
+	summary := Summary{1: leader, 2: chaser}
+
+	assert.True(t, isLapped(summary, &CompetitorState{CompetitorID: 3, Laps: []Lap{{}}}))
+	assert.False(t, isLapped(summary, leader))
+
+	chaser.Laps[1].FinishTime = ts
+	assert.False(t, isLapped(summary, chaser))
+}
+
+func TestPullIfLapped(t *testing.T) {
+	ts := time.Now()
+	leader := &CompetitorState{CompetitorID: 1, Laps: []Lap{{FinishTime: ts}, {FinishTime: ts}}}
+	evt := Event{ID: EventFinishedLap, CompetitorID: 2, Timestamp: ts}
+
+	t.Run("pulled", func(t *testing.T) {
+		st := &CompetitorState{CompetitorID: 2, Laps: []Lap{{FinishTime: ts}, {}}}
+		cfg := Config{Format: FormatPursuit, PullLapped: true}
+
+		pullIfLapped(cfg, Summary{1: leader, 2: st}, evt, st)
+		assert.Equal(t, StatusLapped, st.Status)
+		assert.Equal(t, ts, st.LastSeenTime)
+	})
+
+	t.Run("pulling disabled", func(t *testing.T) {
+		st := &CompetitorState{CompetitorID: 2, Laps: []Lap{{FinishTime: ts}, {}}}
+		cfg := Config{Format: FormatPursuit}
+
+		pullIfLapped(cfg, Summary{1: leader, 2: st}, evt, st)
+		assert.Equal(t, StatusActive, st.Status)
+	})
+}
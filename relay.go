@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checkHandover checks that the relay is taken over by the next leg of a team
+// and that the competitor of the previous leg has finished, even if disqualified on the way.
+func checkHandover(cfg Config, summary Summary, evt Event) error {
+	team, leg, ok := cfg.teamOf(evt.CompetitorID)
+	if !ok {
+		return fmt.Errorf("competitor(%d) is not a member of any relay team", evt.CompetitorID)
+	}
+	if leg == 0 {
+		return fmt.Errorf("competitor(%d) runs the first leg of team(%d) and can't take over", evt.CompetitorID, team.ID)
+	}
+	previous := team.Members[leg-1]
+	if st, exists := summary[previous]; !exists || st.raceStatus() != StatusFinished {
+		return fmt.Errorf("competitor(%d) of team(%d) has not finished the previous leg", previous, team.ID)
+	}
+	return nil
+}
+
+// TeamResult represents the result of a relay team. A leg is nil if its competitor has never been seen.
+type TeamResult struct {
+	Team
+	Nation      string       // The nation of all members of the team, empty if they are not on the roster
+	Competitors []Competitor // The roster entries of the members in the order of the legs
+	Legs        []*Result
+	FiringLines int
+	Targets     int
+}
+
+// finished reports whether all legs of the team have finished.
+func (r TeamResult) finished() bool {
+	return r.finishedLegs() == len(r.Legs)
+}
+
+// finishedLegs returns the number of legs finished in a row from the first one.
+func (r TeamResult) finishedLegs() int {
+	for i, leg := range r.Legs {
+		if leg == nil || leg.Status != StatusFinished {
+			return i
+		}
+	}
+	return len(r.Legs)
+}
+
+// finishTime returns the time the last leg of a finished team crossed the line.
+// Time penalties given by the jury to any leg move the team's finish.
+func (r TeamResult) finishTime() time.Time {
+	last := r.Legs[len(r.Legs)-1]
+	finish := last.Laps[len(last.Laps)-1].FinishTime
+	for _, leg := range r.Legs {
+		finish = finish.Add(leg.TimePenalty)
+	}
+	return finish
+}
+
+// memberLabel returns the name and the nation of the competitor running the given leg.
+func (r TeamResult) memberLabel(leg int) string {
+	if leg < len(r.Competitors) {
+		return r.Competitors[leg].label()
+	}
+	return ""
+}
+
+func (r TeamResult) String() string {
+	var status string
+	legs := make([]string, 0, len(r.Legs))
+	penaltyLaps, spares, hits, shots := 0, 0, 0, 0
+	var total time.Duration
+	var bouts []Bout
+
+	for i, leg := range r.Legs {
+		if leg == nil {
+			legs = append(legs, fmt.Sprintf("{%d%s, ,}", r.Members[i], r.memberLabel(i)))
+			shots += r.FiringLines * r.Targets
+			continue
+		}
+		penaltyLaps += leg.TotalPenaltyLaps
+		spares += leg.TotalSpares
+		hits += leg.TotalHits
+		shots += leg.shots()
+		bouts = append(bouts, leg.Bouts...)
+		if leg.Status != StatusFinished {
+			legs = append(legs, fmt.Sprintf("{%d%s, ,}", leg.CompetitorID, r.memberLabel(i)))
+			continue
+		}
+		total += leg.TotalRaceDuration
+		legs = append(legs, fmt.Sprintf("{%d%s, %s, %s}", leg.CompetitorID, r.memberLabel(i), formatDuration(leg.TotalRaceDuration), formatDuration(total)))
+	}
+
+	switch finished := r.finishedLegs(); {
+	case finished == len(r.Legs):
+		status = formatDuration(total)
+	case r.Legs[finished] != nil && r.Legs[finished].Status != StatusActive && r.Legs[finished].Status != StatusNotStarted:
+		status = r.Legs[finished].status()
+	case finished == 0:
+		status = StatusNotStarted.String()
+	default:
+		status = StatusCantContinue.String()
+	}
+
+	var nation string
+	if r.Nation != "" {
+		nation = fmt.Sprintf(" (%s)", r.Nation)
+	}
+
+	return fmt.Sprintf("[%s] %d%s [%s] %d+%d %d/%d%s",
+		status,
+		r.ID,
+		nation,
+		strings.Join(legs, ", "),
+		penaltyLaps,
+		spares,
+		hits,
+		shots,
+		formatPositionStats(positionStats(bouts, r.Targets)),
+	)
+}
+
+// teamNation returns the nation shared by all members of the team according to the roster.
+func teamNation(cfg Config, team Team) string {
+	var nation string
+	for i, member := range team.Members {
+		c := cfg.competitor(member)
+		if c.Nation == "" || (i > 0 && c.Nation != nation) {
+			return ""
+		}
+		nation = c.Nation
+	}
+	return nation
+}
+
+// generateRelayReport writes the results of all relay teams.
+// Teams that finished come first in the order they crossed the line,
+// followed by the teams that did not finish sorted by the number of finished legs.
+func generateRelayReport(w io.Writer, cfg Config, summary Summary) {
+	results := make([]TeamResult, 0, len(cfg.Teams))
+	for _, team := range cfg.Teams {
+		result := TeamResult{Team: team, Nation: teamNation(cfg, team), FiringLines: cfg.FiringLines, Targets: cfg.targets()}
+		for _, member := range team.Members {
+			result.Competitors = append(result.Competitors, cfg.competitor(member))
+			var leg *Result
+			if st, exists := summary[member]; exists {
+				leg = &Result{
+					CompetitorState: st,
+					Competitor:      cfg.competitor(member),
+					LapLen:          cfg.LapLen,
+					PenaltyLen:      cfg.PenaltyLen,
+					FiringLines:     cfg.FiringLines,
+					Targets:         cfg.targets(),
+				}
+			}
+			result.Legs = append(result.Legs, leg)
+		}
+		results = append(results, result)
+	}
+
+	slices.SortStableFunc(results, func(a, b TeamResult) int {
+		switch {
+		case a.finished() && b.finished():
+			return a.finishTime().Compare(b.finishTime())
+		case a.finished():
+			return -1
+		case b.finished():
+			return 1
+		default:
+			return b.finishedLegs() - a.finishedLegs()
+		}
+	})
+
+	for _, v := range results {
+		fmt.Fprintln(w, v)
+	}
+
+	writeAccuracy(w, cfg.targets(), summary)
+}
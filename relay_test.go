@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamOf(t *testing.T) {
+	cfg := Config{Teams: []Team{{ID: 1, Members: []int{11, 12}}, {ID: 2, Members: []int{21, 22}}}}
+
+	team, leg, ok := cfg.teamOf(22)
+	assert.True(t, ok)
+	assert.Equal(t, 2, team.ID)
+	assert.Equal(t, 1, leg)
+
+	_, _, ok = cfg.teamOf(3)
+	assert.False(t, ok)
+}
+
+func TestCheckHandover(t *testing.T) {
+	cfg := Config{Format: FormatRelay, Teams: []Team{{ID: 1, Members: []int{11, 12, 13}}}}
+
+	tests := []struct {
+		name    string
+		summary Summary
+		id      int
+		wantErr bool
+	}{
+		{name: "previous leg finished", summary: Summary{11: {Status: StatusFinished}}, id: 12},
+		{name: "previous leg still running", summary: Summary{11: {}}, id: 12, wantErr: true},
+		{name: "previous leg never seen", summary: Summary{11: {Status: StatusFinished}}, id: 13, wantErr: true},
+		{name: "first leg", summary: Summary{}, id: 11, wantErr: true},
+		{name: "not a team member", summary: Summary{}, id: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHandover(cfg, tt.summary, Event{ID: EventHandover, CompetitorID: tt.id})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTeamResultString(t *testing.T) {
+	ts := must(time.Parse(time.TimeOnly, "14:20:00"))
+	first := &CompetitorState{
+		CompetitorID:      11,
+		Status:            StatusFinished,
+		TotalRaceDuration: 20 * time.Minute,
+		TotalPenaltyLaps:  1,
+		TotalSpares:       2,
+		TotalHits:         5,
+		Laps:              []Lap{{FinishTime: ts}},
+	}
+
+	t.Run("finished", func(t *testing.T) {
+		second := &CompetitorState{
+			CompetitorID:      12,
+			Status:            StatusFinished,
+			TotalRaceDuration: 19 * time.Minute,
+			TotalSpares:       1,
+			TotalHits:         5,
+			Laps:              []Lap{{FinishTime: ts.Add(19 * time.Minute)}},
+		}
+		r := TeamResult{
+			Team:        Team{ID: 1, Members: []int{11, 12}},
+			Legs:        []*Result{{CompetitorState: first, FiringLines: 1, Targets: 5}, {CompetitorState: second, FiringLines: 1, Targets: 5}},
+			FiringLines: 1,
+			Targets:     5,
+		}
+		assert.True(t, r.finished())
+		assert.Equal(t, ts.Add(19*time.Minute), r.finishTime())
+		assert.Equal(t, "[00:39:00.000] 1 [{11, 00:20:00.000, 00:20:00.000}, {12, 00:19:00.000, 00:39:00.000}] 1+3 10/13", r.String())
+	})
+
+	t.Run("not finished", func(t *testing.T) {
+		second := &CompetitorState{CompetitorID: 12, Status: StatusCantContinue}
+		r := TeamResult{
+			Team:        Team{ID: 1, Members: []int{11, 12, 13}},
+			Legs:        []*Result{{CompetitorState: first, FiringLines: 1, Targets: 5}, {CompetitorState: second, FiringLines: 1, Targets: 5}, nil},
+			FiringLines: 1,
+			Targets:     5,
+		}
+		assert.False(t, r.finished())
+		assert.Equal(t, 1, r.finishedLegs())
+		assert.Equal(t, "[DNF] 1 [{11, 00:20:00.000, 00:20:00.000}, {12, ,}, {13, ,}] 1+2 5/17", r.String())
+	})
+}
+
+func TestGenerateRelayReport(t *testing.T) {
+	ts := must(time.Parse(time.TimeOnly, "14:20:00"))
+	cfg := Config{
+		Format:      FormatRelay,
+		FiringLines: 1,
+		Teams:       []Team{{ID: 1, Members: []int{11}}, {ID: 2, Members: []int{21}}, {ID: 3, Members: []int{31}}},
+	}
+	summary := Summary{
+		11: {CompetitorID: 11, Status: StatusFinished, TotalRaceDuration: 21 * time.Minute, Laps: []Lap{{FinishTime: ts.Add(time.Minute)}}},
+		21: {CompetitorID: 21, Status: StatusFinished, TotalRaceDuration: 20 * time.Minute, Laps: []Lap{{FinishTime: ts}}},
+	}
+
+	var buf bytes.Buffer
+	generateReport(&buf, cfg, summary)
+
+	want := "[00:20:00.000] 2 [{21, 00:20:00.000, 00:20:00.000}] 0+0 0/5\n" +
+		"[00:21:00.000] 1 [{11, 00:21:00.000, 00:21:00.000}] 0+0 0/5\n" +
+		"[DNS] 3 [{31, ,}] 0+0 0/5\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestGenerateRelayReportTimePenalty(t *testing.T) {
+	ts := must(time.Parse(time.TimeOnly, "14:10:00"))
+	cfg := Config{
+		Format:      FormatRelay,
+		FiringLines: 1,
+		Teams:       []Team{{ID: 1, Members: []int{11}}, {ID: 2, Members: []int{21}}},
+	}
+	summary := Summary{
+		11: {CompetitorID: 11, Status: StatusFinished, TotalRaceDuration: 11 * time.Minute, TimePenalty: time.Minute,
+			Laps: []Lap{{FinishTime: ts}}},
+		21: {CompetitorID: 21, Status: StatusFinished, TotalRaceDuration: 10*time.Minute + 5*time.Second,
+			Laps: []Lap{{FinishTime: ts.Add(5 * time.Second)}}},
+	}
+
+	var buf bytes.Buffer
+	generateReport(&buf, cfg, summary)
+
+	// Team 1 crossed the line first, but the jury's time penalty puts it behind team 2.
+	want := "[00:10:05.000] 2 [{21, 00:10:05.000, 00:10:05.000}] 0+0 0/5\n" +
+		"[00:11:00.000] 1 [{11, 00:11:00.000, 00:11:00.000}] 0+0 0/5\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestGenerateRelayReportRoster(t *testing.T) {
+	cfg := Config{
+		Format:      FormatRelay,
+		FiringLines: 1,
+		Teams:       []Team{{ID: 1, Members: []int{11, 12}}, {ID: 2, Members: []int{21}}},
+		competitors: map[int]Competitor{
+			11: {ID: 11, Name: "Anna Berg", Nation: "NOR"},
+			12: {ID: 12, Name: "Ida Nilsen", Nation: "NOR"},
+			21: {ID: 21, Name: "Lena Vogel"},
+		},
+	}
+	summary := Summary{11: {CompetitorID: 11, Status: StatusCantContinue}}
+
+	var buf bytes.Buffer
+	generateReport(&buf, cfg, summary)
+
+	want := "[DNF] 1 (NOR) [{11 Anna Berg (NOR), ,}, {12 Ida Nilsen (NOR), ,}] 0+0 0/10\n" +
+		"[DNS] 2 [{21 Lena Vogel, ,}] 0+0 0/5\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestRelayLegs(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "14:00:00"))
+	cfg := Config{Format: FormatRelay, Start: Time{start}, SpareRounds: 1, Teams: []Team{{ID: 1, Members: []int{11, 12}}}}
+
+	t.Run("first leg starts at the start time", func(t *testing.T) {
+		st := &CompetitorState{}
+		require.NoError(t, updateState(cfg, Event{ID: EventRegistered, CompetitorID: 11}, st))
+		assert.Equal(t, start, st.ScheduledStartTime)
+	})
+
+	t.Run("next leg starts on handover", func(t *testing.T) {
+		st := &CompetitorState{}
+		require.NoError(t, updateState(cfg, Event{ID: EventRegistered, CompetitorID: 12}, st))
+		assert.True(t, st.ScheduledStartTime.IsZero())
+		assert.Error(t, updateState(cfg, Event{ID: EventStartedRace, CompetitorID: 12}, st))
+
+		handover := start.Add(20 * time.Minute)
+		require.NoError(t, updateState(cfg, Event{ID: EventHandover, CompetitorID: 12, Timestamp: handover}, st))
+		assert.Equal(t, handover, st.ActualStartTime)
+		assert.Len(t, st.Laps, 1)
+
+		assert.Error(t, updateState(cfg, Event{ID: EventHandover, CompetitorID: 12, Timestamp: handover}, st))
+	})
+
+	t.Run("not a team member", func(t *testing.T) {
+		st := &CompetitorState{}
+		assert.Error(t, updateState(cfg, Event{ID: EventRegistered, CompetitorID: 3}, st))
+	})
+
+	t.Run("spare rounds", func(t *testing.T) {
+		st := &CompetitorState{}
+		assert.Error(t, updateState(cfg, Event{ID: EventSpareLoaded}, st))
+
+		st.Bouts = []Bout{{FiringRange: 1}}
+		require.NoError(t, updateState(cfg, Event{ID: EventSpareLoaded}, st))
+		assert.Equal(t, 1, st.Bouts[0].Spares)
+		assert.Equal(t, 1, st.TotalSpares)
+
+		assert.Error(t, updateState(cfg, Event{ID: EventSpareLoaded}, st))
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"io"
 	"time"
@@ -10,18 +11,20 @@ import (
 
 type Result struct {
 	*CompetitorState
-	LapLen      int
+	Competitor  Competitor
+	LapLen      LapLengths
 	PenaltyLen  int
 	FiringLines int
+	Targets     int
 }
 
 func (r Result) String() string {
 	var lapsStr string
-	for _, lap := range r.Laps {
+	for i, lap := range r.Laps {
 		if lap.Duration == 0 {
 			lapsStr += "{,}, "
 		} else {
-			lapsStr += fmt.Sprintf("{%s, %.3f}, ", formatDuration(lap.Duration), calculateAverageSpeed(r.LapLen, lap.Duration))
+			lapsStr += fmt.Sprintf("{%s, %.3f}, ", formatDuration(lap.Duration), calculateAverageSpeed(r.LapLen.Of(i), lap.Duration))
 		}
 	}
 	if len(r.Laps) > 0 {
@@ -39,26 +42,40 @@ func (r Result) String() string {
 		penaltyStr += "}"
 	}
 
-	var status string
-	switch r.Status {
-	case StatusDisqualified:
-		status = "NotStarted"
-	case StatusCantContinue:
-		status = "NotFinished"
-	case StatusFinished:
-		status = formatDuration(r.TotalRaceDuration)
+	var reasonStr string
+	if r.StatusReason != "" {
+		reasonStr = fmt.Sprintf(" (%s)", r.StatusReason)
+	}
+	if r.TimePenalty != 0 {
+		reasonStr += fmt.Sprintf(" (time penalty %s)", formatSignedDuration(r.TimePenalty))
 	}
 
-	return fmt.Sprintf("[%s] %d [%s] %s %d/%d",
-		status,
+	return fmt.Sprintf("[%s] %d%s [%s] %s %d/%d%s%s",
+		r.status(),
 		r.CompetitorID,
+		r.Competitor.label(),
 		lapsStr,
 		penaltyStr,
 		r.TotalHits,
-		r.FiringLines*NumberOfTargets,
+		r.shots(),
+		formatPositionStats(positionStats(r.Bouts, r.Targets)),
+		reasonStr,
 	)
 }
 
+// shots returns the number of shots fired by the competitor on all firing lines.
+func (r Result) shots() int {
+	return shotsFired(r.TotalShots, r.FiringLines, r.Targets, r.TotalSpares)
+}
+
+// status returns the total time of a finished competitor or the abbreviation of his/her status.
+func (r Result) status() string {
+	if r.Status == StatusFinished {
+		return formatDuration(r.TotalRaceDuration)
+	}
+	return r.Status.String()
+}
+
 func formatDuration(d time.Duration) string {
 	hours := d / time.Hour
 	d -= hours * time.Hour
@@ -74,45 +91,97 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, milliseconds)
 }
 
+// formatSignedDuration formats a duration with an explicit sign, e.g. "+00:01:00.000".
+func formatSignedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	return "+" + formatDuration(d)
+}
+
 func generateReport(w io.Writer, cfg Config, summary Summary) {
+	if cfg.Format == FormatRelay {
+		generateRelayReport(w, cfg, summary)
+		return
+	}
+
+	results := rankResults(cfg, summary)
+	if cfg.hasCategories() {
+		writeCategoryResults(w, results)
+	} else {
+		for _, v := range results {
+			fmt.Fprintln(w, v)
+		}
+	}
+
+	if cfg.TeamClassification != nil {
+		writeTeamClassification(w, cfg, results)
+	}
+
+	writeAccuracy(w, cfg.targets(), summary)
+}
+
+// rankResults returns the results of all competitors in the order of the final report.
+func rankResults(cfg Config, summary Summary) []Result {
 	var notStarted []Result
+	var disqualified []Result
 	var cantContinue []Result
+	var unfinished []Result
 	var finishedRace []Result
+	var lapped []Result
 
 	// Sort competitors into categories based on their final state.
 	for _, competitorState := range summary {
 		competitorResult := Result{
 			CompetitorState: competitorState,
+			Competitor:      cfg.competitor(competitorState.CompetitorID),
 			LapLen:          cfg.LapLen,
 			PenaltyLen:      cfg.PenaltyLen,
 			FiringLines:     cfg.FiringLines,
+			Targets:         cfg.targets(),
 		}
 		switch competitorState.Status {
-		case StatusDisqualified:
+		case StatusNotStarted:
 			notStarted = append(notStarted, competitorResult)
+		case StatusDisqualified:
+			disqualified = append(disqualified, competitorResult)
 		case StatusCantContinue:
 			cantContinue = append(cantContinue, competitorResult)
+		case StatusUnfinished:
+			unfinished = append(unfinished, competitorResult)
 		case StatusFinished:
 			finishedRace = append(finishedRace, competitorResult)
+		case StatusLapped:
+			lapped = append(lapped, competitorResult)
 		}
 	}
 
 	// Sort competitors within each category.
 	sortByScheduledStartTime(notStarted)
+	sortByScheduledStartTime(disqualified)
 	sortByLastSeenTime(cantContinue)
-	sortByTotalRaceDuration(finishedRace)
-
-	for _, v := range notStarted {
-		fmt.Fprintln(w, v)
+	sortByCompletedLaps(unfinished)
+	if cfg.ranksByFinishTime() {
+		sortByFinishTime(finishedRace)
+	} else {
+		sortByTotalRaceDuration(finishedRace)
 	}
+	sortByCompletedLaps(lapped)
 
-	for _, v := range cantContinue {
-		fmt.Fprintln(w, v)
-	}
+	return slices.Concat(notStarted, disqualified, cantContinue, unfinished, finishedRace, lapped)
+}
 
-	for _, v := range finishedRace {
-		fmt.Fprintln(w, v)
+// ranks numbers the finished competitors in the order of the final report.
+func ranks(results []Result) map[int]int {
+	ranks := make(map[int]int, len(results))
+	rank := 0
+	for _, r := range results {
+		if r.Status == StatusFinished {
+			rank++
+			ranks[r.CompetitorID] = rank
+		}
 	}
+	return ranks
 }
 
 func calculateAverageSpeed(distance int, duration time.Duration) float64 {
@@ -158,3 +227,24 @@ func sortByTotalRaceDuration(states []Result) {
 		return 0
 	})
 }
+
+func sortByFinishTime(states []Result) {
+	slices.SortFunc(states, func(a, b Result) int {
+		// A time penalty given by the jury moves the competitor's finish.
+		aFinish := a.Laps[len(a.Laps)-1].FinishTime.Add(a.TimePenalty)
+		bFinish := b.Laps[len(b.Laps)-1].FinishTime.Add(b.TimePenalty)
+		return aFinish.Compare(bFinish)
+	})
+}
+
+// sortByCompletedLaps puts competitors who completed more laps first.
+// Competitors with the same number of laps are sorted by the time they were last seen.
+func sortByCompletedLaps(states []Result) {
+	slices.SortFunc(states, func(a, b Result) int {
+		return cmp.Or(
+			b.completedLaps()-a.completedLaps(),
+			a.LastSeenTime.Compare(b.LastSeenTime),
+			a.CompetitorID-b.CompetitorID,
+		)
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultShots(t *testing.T) {
+	tests := []struct {
+		name  string
+		state CompetitorState
+		want  int
+	}{
+		{name: "no shots reported", state: CompetitorState{}, want: 10},
+		{name: "spare rounds loaded", state: CompetitorState{TotalSpares: 3}, want: 13},
+		{name: "shots reported", state: CompetitorState{TotalShots: 12, TotalSpares: 2}, want: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{CompetitorState: &tt.state, FiringLines: 2, Targets: 5}
+			assert.Equal(t, tt.want, r.shots())
+		})
+	}
+}
+
+func TestResultStringLapLengths(t *testing.T) {
+	st := &CompetitorState{
+		CompetitorID:      1,
+		Status:            StatusFinished,
+		TotalRaceDuration: 20 * time.Minute,
+		Laps:              []Lap{{Duration: 10 * time.Minute}, {Duration: 10 * time.Minute}},
+		TotalHits:         5,
+	}
+	r := Result{CompetitorState: st, LapLen: LapLengths{3000, 3600}, FiringLines: 1, Targets: 5}
+
+	assert.Equal(t, "[00:20:00.000] 1 [{00:10:00.000, 5.000}, {00:10:00.000, 6.000}] {,} 5/5", r.String())
+}
+
+func TestResultStringPositions(t *testing.T) {
+	st := &CompetitorState{
+		CompetitorID: 1,
+		Status:       StatusCantContinue,
+		TotalHits:    8,
+		Bouts:        []Bout{{Position: PositionProne, Hits: 5}, {Position: PositionStanding, Hits: 3}},
+	}
+	r := Result{CompetitorState: st, FiringLines: 2, Targets: 5}
+
+	assert.Equal(t, "[DNF] 1 [] {,} 8/10 {prone 5/5, standing 3/5}", r.String())
+}
+
+func TestResultStringCompetitor(t *testing.T) {
+	st := &CompetitorState{CompetitorID: 1, Status: StatusCantContinue, TotalHits: 5}
+	r := Result{CompetitorState: st, Competitor: Competitor{ID: 1, Name: "Anna Berg", Nation: "NOR"}, FiringLines: 1, Targets: 5}
+
+	assert.Equal(t, "[DNF] 1 Anna Berg (NOR) [] {,} 5/5", r.String())
+
+	entries, err := readResults(strings.NewReader(r.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, []ResultEntry{{CompetitorID: 1, Status: "DNF"}}, entries)
+}
+
+func TestGenerateReportUnfinished(t *testing.T) {
+	start := must(time.Parse(time.TimeOnly, "09:00:00"))
+	inCh := make(chan Event, 6)
+	inCh <- Event{ID: EventRegistered, CompetitorID: 1, Timestamp: start}
+	inCh <- Event{ID: EventRegistered, CompetitorID: 2, Timestamp: start}
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 2, Timestamp: start, Extra: []string{"09:10:00"}}
+	inCh <- Event{ID: EventSetStartTime, CompetitorID: 1, Timestamp: start, Extra: []string{"09:10:30"}}
+	inCh <- Event{ID: EventStartedRace, CompetitorID: 1, Timestamp: start.Add(10*time.Minute + 30*time.Second)}
+	inCh <- Event{ID: EventFinishedLap, CompetitorID: 1, Timestamp: start.Add(20*time.Minute + 30*time.Second)}
+	close(inCh)
+
+	cfg := Config{Laps: 2, LapLen: LapLengths{3000}, FiringLines: 1, StartDelta: Duration{30 * time.Second}}
+
+	var log bytes.Buffer
+	summary := processEvents(&log, cfg, inCh)
+
+	var buf bytes.Buffer
+	generateReport(&buf, cfg, summary)
+
+	want := "[DNS] 2 [] {,} 0/5 (no start recorded)\n" +
+		"[UNF] 1 [{00:10:00.000, 5.000}, {,}] {,} 0/5 (no finish recorded after 1 of 2 laps)\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestResultStringTimePenalty(t *testing.T) {
+	st := &CompetitorState{
+		CompetitorID:      1,
+		Status:            StatusFinished,
+		TotalRaceDuration: 11 * time.Minute,
+		TimePenalty:       time.Minute,
+		Laps:              []Lap{{Duration: 10 * time.Minute}},
+		TotalHits:         5,
+	}
+	r := Result{CompetitorState: st, LapLen: LapLengths{3000}, FiringLines: 1, Targets: 5}
+
+	assert.Equal(t, "[00:11:00.000] 1 [{00:10:00.000, 5.000}] {,} 5/5 (time penalty +00:01:00.000)", r.String())
+}
+
+func TestFormatSignedDuration(t *testing.T) {
+	assert.Equal(t, "+00:01:00.000", formatSignedDuration(time.Minute))
+	assert.Equal(t, "-00:00:10.500", formatSignedDuration(-10*time.Second-500*time.Millisecond))
+}
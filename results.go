@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultEntry is a single line of a final report read back from a results file.
+type ResultEntry struct {
+	CompetitorID int
+	Status       string        // The raw status field, e.g. "NotFinished" or the total time
+	Time         time.Duration // The total time, zero unless the competitor has finished
+	Finished     bool
+}
+
+// readResults reads the final report lines from the output of a previous run.
+// Event log lines and error lines are skipped, the order of the report is preserved.
+func readResults(r io.Reader) ([]ResultEntry, error) {
+	var entries []ResultEntry
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		// Results ranked within a category are prefixed with the rank, e.g. "1.".
+		if len(parts) > 0 && strings.HasSuffix(parts[0], ".") {
+			if _, err := strconv.Atoi(strings.TrimSuffix(parts[0], ".")); err == nil {
+				parts = parts[1:]
+			}
+		}
+		if len(parts) < 2 || !strings.HasPrefix(parts[0], "[") || !strings.HasSuffix(parts[0], "]") {
+			continue
+		}
+
+		// Event log lines have a sentence instead of the competitor ID in the second field.
+		cid, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		entry := ResultEntry{
+			CompetitorID: cid,
+			Status:       strings.Trim(parts[0], "[]"),
+		}
+		if d, err := parseClockDuration(entry.Status); err == nil {
+			entry.Time = d
+			entry.Finished = true
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadResults(t *testing.T) {
+	input := `[09:05:59.867] The competitor(1) registered
+[ERROR] update failed error has occured but was ignored: invalid start time
+[NotFinished] 1 [{00:29:03.872, 2.094}, {,}] {00:01:52.476, 0.445} 4/5
+[00:25:18.356] 2 [{00:12:39.746, 4.607}, {00:12:38.610, 4.614}] {00:01:40.000, 3.000} 8/10
+`
+	entries, err := readResults(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, ResultEntry{CompetitorID: 1, Status: "NotFinished"}, entries[0])
+	assert.Equal(t, ResultEntry{
+		CompetitorID: 2,
+		Status:       "00:25:18.356",
+		Time:         25*time.Minute + 18*time.Second + 356*time.Millisecond,
+		Finished:     true,
+	}, entries[1])
+}
+
+func TestReadResultsEmpty(t *testing.T) {
+	entries, err := readResults(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
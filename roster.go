@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Competitor describes a competitor on the roster.
+type Competitor struct {
+	ID       int    `json:"id"`
+	Bib      int    `json:"bib"`
+	Name     string `json:"name"`
+	Nation   string `json:"nation"`
+	Club     string `json:"club"`
+	Gender   string `json:"gender"`
+	Category string `json:"category"`
+}
+
+// String returns the name and the nation of the competitor, e.g. "Anna Berg (NOR)".
+func (c Competitor) String() string {
+	switch {
+	case c.Name != "" && c.Nation != "":
+		return fmt.Sprintf("%s (%s)", c.Name, c.Nation)
+	case c.Nation != "":
+		return fmt.Sprintf("(%s)", c.Nation)
+	default:
+		return c.Name
+	}
+}
+
+// label returns the name and the nation of the competitor prefixed with a space to follow the competitor ID,
+// or an empty string if the competitor is not on the roster.
+func (c Competitor) label() string {
+	if s := c.String(); s != "" {
+		return " " + s
+	}
+	return ""
+}
+
+// loadRoster reads the roster from a CSV or a JSON file depending on its extension.
+// A CSV file must have a header naming the columns, only the id column is required.
+func loadRoster(path string) (map[int]Competitor, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var competitors []Competitor
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		competitors, err = readRosterCSV(file)
+	case ".json":
+		err = json.NewDecoder(file).Decode(&competitors)
+	default:
+		return nil, fmt.Errorf("unsupported roster format: %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing roster: %w", err)
+	}
+
+	roster := make(map[int]Competitor, len(competitors))
+	bibs := make(map[int]int, len(competitors))
+	for _, c := range competitors {
+		if _, exists := roster[c.ID]; exists {
+			return nil, fmt.Errorf("competitor(%d) is on the roster twice", c.ID)
+		}
+		if other, exists := bibs[c.Bib]; exists && c.Bib != 0 {
+			return nil, fmt.Errorf("bib %d is given to competitor(%d) and competitor(%d)", c.Bib, other, c.ID)
+		}
+		roster[c.ID] = c
+		bibs[c.Bib] = c.ID
+	}
+
+	return roster, nil
+}
+
+// readRosterCSV reads the competitors from CSV records with a header.
+func readRosterCSV(r io.Reader) ([]Competitor, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, errors.New("missing 'id' column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var competitors []Competitor
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return competitors, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		c := Competitor{
+			Name:     field(record, "name"),
+			Nation:   field(record, "nation"),
+			Club:     field(record, "club"),
+			Gender:   field(record, "gender"),
+			Category: field(record, "category"),
+		}
+		if c.ID, err = strconv.Atoi(field(record, "id")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid id: %w", line, err)
+		}
+		if bib := field(record, "bib"); bib != "" {
+			if c.Bib, err = strconv.Atoi(bib); err != nil {
+				return nil, fmt.Errorf("line %d: invalid bib: %w", line, err)
+			}
+		}
+		competitors = append(competitors, c)
+	}
+}
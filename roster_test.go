@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRoster(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadRosterCSV(t *testing.T) {
+	roster, err := loadRoster("examples/startlist/roster.csv")
+	require.NoError(t, err)
+	assert.Len(t, roster, 8)
+	assert.Equal(t, Competitor{
+		ID:       4,
+		Bib:      4,
+		Name:     "Elin Lund",
+		Nation:   "SWE",
+		Club:     "Östersund SK",
+		Gender:   "F",
+		Category: "Junior",
+	}, roster[4])
+}
+
+func TestLoadRosterJSON(t *testing.T) {
+	roster, err := loadRoster("examples/relay/roster.json")
+	require.NoError(t, err)
+	assert.Len(t, roster, 4)
+	assert.Equal(t, "Lena Vogel (GER)", roster[21].String())
+}
+
+func TestLoadRosterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "unsupported format", file: "roster.txt", content: "", wantErr: "unsupported roster format"},
+		{name: "missing id column", file: "roster.csv", content: "name\nAnna\n", wantErr: "missing 'id' column"},
+		{name: "invalid id", file: "roster.csv", content: "id,name\nx,Anna\n", wantErr: "line 2: invalid id"},
+		{name: "invalid bib", file: "roster.csv", content: "id,bib\n1,x\n", wantErr: "line 2: invalid bib"},
+		{name: "duplicate id", file: "roster.csv", content: "id\n1\n1\n", wantErr: "competitor(1) is on the roster twice"},
+		{name: "duplicate bib", file: "roster.json", content: `[{"id": 1, "bib": 7}, {"id": 2, "bib": 7}]`, wantErr: "bib 7"},
+		{name: "invalid json", file: "roster.json", content: `{"id": 1}`, wantErr: "parsing roster"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRoster(writeRoster(t, tt.file, tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReadRosterCSVColumnOrder(t *testing.T) {
+	competitors, err := readRosterCSV(strings.NewReader("Nation, ID\nNOR, 3\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Competitor{{ID: 3, Nation: "NOR"}}, competitors)
+}
+
+func TestCompetitorString(t *testing.T) {
+	assert.Equal(t, "Anna Berg (NOR)", Competitor{Name: "Anna Berg", Nation: "NOR"}.String())
+	assert.Equal(t, "Anna Berg", Competitor{Name: "Anna Berg"}.String())
+	assert.Equal(t, "(NOR)", Competitor{Nation: "NOR"}.String())
+	assert.Equal(t, "", Competitor{ID: 1}.label())
+	assert.Equal(t, " Anna Berg", Competitor{Name: "Anna Berg"}.label())
+}
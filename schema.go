@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemaDialect is the JSON Schema version of the generated schemas.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// configFieldHints holds the descriptions and constraints of the config fields by their JSON path.
+var configFieldHints = map[string]map[string]any{
+	"laps":        {"description": "Amount of laps for main distance", "minimum": 1},
+	"lapLen":      {"description": "Length of each main lap, or a list with the length of every lap in order"},
+	"penaltyLen":  {"description": "Length of each penalty lap"},
+	"firingLines": {"description": "Number of firing lines per lap"},
+	"start":       {"description": "Planned start time for the first competitor"},
+	"startDelta":  {"description": "Planned interval between starts"},
+	"format": {
+		"description": "Competition format, empty for an interval start",
+		"enum":        []string{FormatInterval, FormatPursuit, FormatMass, FormatRelay},
+	},
+	"seeds":       {"description": "Results of a previous competition the pursuit start times are seeded from"},
+	"pullLapped":  {"description": "Whether lapped competitors are pulled from the course"},
+	"lanes":       {"description": "Number of shooting lanes on the range (mass start only)"},
+	"spareRounds": {"description": "Number of spare rounds that may be loaded by hand per bout"},
+	"teams":       {"description": "Relay teams, each with an ID and the members in the order they run the legs"},
+	"penaltyTime": {"description": "Time added for every missed target instead of a penalty lap"},
+	"positions":   {"description": "Shooting positions of the firing lines in order"},
+	"positions[]": {"enum": []string{PositionProne, PositionStanding}},
+	"targets":     {"description": "Number of targets per bout, 5 by default"},
+	"profile":     {"description": "Name of the race format profile filling in the fields left empty"},
+	"profiles":    {"description": "Custom race format profiles by name"},
+	"startTolerance": {
+		"description": "How late a competitor may start after the start time, startDelta by default, zero to start exactly on time",
+	},
+	"roster":             {"description": "Roster file (CSV or JSON) with the names, nations and categories of the competitors"},
+	"id":                 {"description": "Name of a competition in a config with several competitions"},
+	"categories":         {"description": "Roster categories entered in the competition, all by default"},
+	"competitions":       {"description": "Competitions sharing one event stream, inheriting the fields they don't set"},
+	"teamClassification": {"description": "Classification of the nations or clubs by their best finishers"},
+	"teamClassification.by": {
+		"enum": []string{TeamsByNation, TeamsByClub},
+	},
+	"teamClassification.best": {"minimum": 1},
+	"teamClassification.scoring": {
+		"enum": []string{"", ScoringTime, ScoringPoints},
+	},
+	"teamClassification.tieBreak": {
+		"enum": []string{"", TieBreakBest, TieBreakLast},
+	},
+	"profiles{}.format": {
+		"enum": []string{FormatInterval, FormatPursuit, FormatMass, FormatRelay},
+	},
+	"profiles{}.positions[]": {"enum": []string{PositionProne, PositionStanding}},
+}
+
+// configSchema returns the JSON Schema of the config generated from the Config type.
+func configSchema() map[string]any {
+	schema := typeSchema(reflect.TypeFor[Config](), "")
+	schema["$schema"] = schemaDialect
+	schema["title"] = "goathlon config"
+	return schema
+}
+
+// typeSchema returns the schema of a config type. The path names the field in configFieldHints.
+// Times, lists, maps and pointers accept null, which is how the config is printed when they are empty.
+func typeSchema(t reflect.Type, path string) map[string]any {
+	var schema map[string]any
+	switch t {
+	case reflect.TypeFor[Time]():
+		schema = nullable(map[string]any{"type": "string", "pattern": "^" + clockPattern + "$"})
+	case reflect.TypeFor[Duration]():
+		schema = map[string]any{
+			"description": `Clock time ("00:00:30.500"), Go duration ("1m30s") or number of seconds`,
+			"type":        []string{"string", "number"},
+		}
+	case reflect.TypeFor[LapLengths]():
+		length := map[string]any{"type": "integer", "minimum": 1}
+		schema = nullable(map[string]any{
+			"oneOf": []any{length, map[string]any{"type": "array", "items": length, "minItems": 1}},
+		})
+	case reflect.TypeFor[Config]():
+		if path != "" {
+			// Competitions are configs themselves.
+			schema = map[string]any{"$ref": "#"}
+			break
+		}
+		schema = objectSchema(t, path)
+	default:
+		switch t.Kind() {
+		case reflect.Pointer:
+			return nullable(typeSchema(t.Elem(), path))
+		case reflect.Struct:
+			schema = objectSchema(t, path)
+		case reflect.Slice:
+			schema = nullable(map[string]any{"type": "array", "items": typeSchema(t.Elem(), path+"[]")})
+		case reflect.Map:
+			schema = nullable(map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), path+"{}")})
+		case reflect.String:
+			schema = map[string]any{"type": "string"}
+		case reflect.Bool:
+			schema = map[string]any{"type": "boolean"}
+		case reflect.Int:
+			schema = map[string]any{"type": "integer", "minimum": 0}
+		default:
+			panic(fmt.Sprintf("no schema for %s", t))
+		}
+	}
+
+	for key, value := range configFieldHints[path] {
+		schema[key] = value
+	}
+	return schema
+}
+
+// nullable returns the schema accepting null as well.
+func nullable(schema map[string]any) map[string]any {
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []string{t, "null"}
+	case []string:
+		schema["type"] = append(t, "null")
+	case nil:
+		if oneOf, ok := schema["oneOf"].([]any); ok {
+			schema["oneOf"] = append(oneOf, map[string]any{"type": "null"})
+		}
+	}
+	return schema
+}
+
+// objectSchema returns the schema of a struct with the fields that have a JSON name.
+func objectSchema(t reflect.Type, path string) map[string]any {
+	properties := make(map[string]any)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := f.Tag.Get("json")
+		if !f.IsExported() || name == "" {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		properties[name] = typeSchema(f.Type, fieldPath)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// eventLinePattern returns the regular expression of an event line with the given event.
+func eventLinePattern(spec EventSpec) string {
+	return `^\s*\[` + clockPattern + `\](\s+@\S+)?\s+` + strconv.Itoa(spec.ID) + `\s+\d+` + spec.pattern + `\s*$`
+}
+
+// eventsSchema returns the JSON Schema of an event line generated from the event catalog.
+// An event line is valid if it matches the pattern of exactly one incoming event.
+func eventsSchema() map[string]any {
+	events := make([]any, 0, len(eventCatalog))
+	for _, spec := range eventCatalog {
+		usage := strings.TrimSpace(fmt.Sprintf("[time] [@competition] %d competitorID %s", spec.ID, spec.Params))
+		events = append(events, map[string]any{
+			"title":       usage,
+			"description": spec.Description,
+			"pattern":     eventLinePattern(spec),
+		})
+	}
+	return map[string]any{
+		"$schema":     schemaDialect,
+		"title":       "goathlon event line",
+		"description": "[time] [@competition] eventID competitorID extraParams",
+		"type":        "string",
+		"oneOf":       events,
+	}
+}
+
+// schemas holds the generators of the published schemas by name.
+var schemas = map[string]func() map[string]any{
+	"config": configSchema,
+	"events": eventsSchema,
+}
+
+// writeSchema writes the schema with the given name as indented JSON.
+func writeSchema(w io.Writer, name string) error {
+	schema, ok := schemas[name]
+	if !ok {
+		return fmt.Errorf("unknown schema: %s", name)
+	}
+	data, err := json.MarshalIndent(schema(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
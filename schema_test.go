@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFiles(t *testing.T) {
+	for name := range schemas {
+		want, err := os.ReadFile(filepath.Join("schema", name+".schema.json"))
+		assert.NoError(t, err, name)
+
+		var out bytes.Buffer
+		assert.NoError(t, writeSchema(&out, name))
+		assert.Equal(t, string(want), out.String(), "schema/%s.schema.json is out of date, regenerate it with `go run . schema %s`", name, name)
+	}
+
+	assert.EqualError(t, writeSchema(&bytes.Buffer{}, "results"), "unknown schema: results")
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := configSchema()
+	properties := schema["properties"].(map[string]any)
+
+	for field := range configFields() {
+		assert.Contains(t, properties, field)
+	}
+	assert.Equal(t, map[string]any{"$ref": "#"}, properties["competitions"].(map[string]any)["items"])
+
+	laps := properties["laps"].(map[string]any)
+	assert.Equal(t, "integer", laps["type"])
+	assert.Equal(t, 1, laps["minimum"])
+
+	by := properties["teamClassification"].(map[string]any)["properties"].(map[string]any)["by"].(map[string]any)
+	assert.Equal(t, []string{TeamsByNation, TeamsByClub}, by["enum"])
+
+	// Every field of the example configs is known to the schema.
+	paths, err := filepath.Glob("examples/*/config.json")
+	assert.NoError(t, err)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		var cfg map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(data, &cfg), path)
+		for field := range cfg {
+			assert.Contains(t, properties, field, path)
+		}
+	}
+}
+
+// validate checks the value against the schema and returns the problems found.
+// It supports the keywords the generated schemas use, both decoded from JSON.
+func validate(root, schema map[string]any, v any, path string) []string {
+	if ref, ok := schema["$ref"]; ok && ref == "#" {
+		return validate(root, root, v, path)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, s := range oneOf {
+			if len(validate(root, s.(map[string]any), v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return []string{fmt.Sprintf("%s: matches %d schemas of oneOf", path, matched)}
+		}
+	}
+
+	if want, ok := schema["type"]; ok {
+		types, ok := want.([]any)
+		if !ok {
+			types = []any{want}
+		}
+		got := jsonType(v)
+		if !slices.Contains(types, any(got)) && !(got == "integer" && slices.Contains(types, any("number"))) {
+			return []string{fmt.Sprintf("%s: %s is not %v", path, got, want)}
+		}
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, v) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", path, v, enum))
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, ok := v.(float64); ok && n < minimum {
+			problems = append(problems, fmt.Sprintf("%s: %v is less than %v", path, n, minimum))
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if str, ok := v.(string); ok && !regexp.MustCompile(pattern).MatchString(str) {
+			problems = append(problems, fmt.Sprintf("%s: %q doesn't match %s", path, str, pattern))
+		}
+	}
+
+	switch v := v.(type) {
+	case []any:
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(v)) < minItems {
+			problems = append(problems, fmt.Sprintf("%s: fewer than %v items", path, minItems))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validate(root, items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for key, value := range v {
+			switch s, ok := properties[key].(map[string]any); {
+			case ok:
+				problems = append(problems, validate(root, s, value, path+"."+key)...)
+			case schema["additionalProperties"] == false:
+				problems = append(problems, fmt.Sprintf("%s: unknown property %s", path, key))
+			default:
+				if s, ok := schema["additionalProperties"].(map[string]any); ok {
+					problems = append(problems, validate(root, s, value, path+"."+key)...)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// jsonType returns the JSON Schema type of a value decoded from JSON.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// decodeJSON marshals the value and decodes it into generic JSON values.
+func decodeJSON[T any](t *testing.T, v any) T {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var decoded T
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
+
+func TestPrintConfigMatchesSchema(t *testing.T) {
+	schema := decodeJSON[map[string]any](t, configSchema())
+
+	paths, err := filepath.Glob("examples/*/config.json")
+	require.NoError(t, err)
+	paths = append(paths, "examples/single/config.yaml")
+	for _, path := range paths {
+		cfg, err := loadConfig(path)
+		require.NoError(t, err, path)
+
+		var out bytes.Buffer
+		require.NoError(t, printConfig(&out, cfg))
+		var printed any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &printed), path)
+
+		assert.Empty(t, validate(schema, schema, printed, "$"), path)
+	}
+
+	// The validator does reject what the schema doesn't allow.
+	bad := map[string]any{"laps": 0.0, "format": "sprint", "teams": "1,2", "start": "noon", "lapLen": []any{}, "unknown": true}
+	assert.Len(t, validate(schema, schema, bad, "$"), 6)
+}
+
+func TestEventsSchema(t *testing.T) {
+	schema := eventsSchema()
+	events := schema["oneOf"].([]any)
+	assert.Len(t, events, len(eventCatalog))
+
+	patterns := make([]*regexp.Regexp, len(events))
+	for i, e := range events {
+		patterns[i] = regexp.MustCompile(e.(map[string]any)["pattern"].(string))
+	}
+	matches := func(line string) int {
+		n := 0
+		for _, p := range patterns {
+			if p.MatchString(line) {
+				n++
+			}
+		}
+		return n
+	}
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{line: "[09:05:59.867] 1 1", want: true},
+		{line: "[09:15:00.841] 2 1 09:30:00.000", want: true},
+		{line: "[09:15:00.841] @men 2 1 09:30:00", want: true},
+		{line: "[09:49:31.659] 5 1 1", want: true},
+		{line: "[09:49:31.659] 5 1 1 3", want: true},
+		{line: "[09:49:33.123] 6 1 1", want: true},
+		{line: "[09:59:03.872] 11 1 Lost in the forest", want: true},
+		{line: "[09:59:03.872] 11 1", want: true},
+		{line: "[10:30:00.000] 15 1 R9 Unsportsmanlike behaviour", want: true},
+		{line: "[10:30:00.000] 16 1 -00:00:10 Time credit", want: true},
+		{line: "[09:15:00.841] 2 1", want: false},
+		{line: "[09:49:31.659] 5 1", want: false},
+		{line: "[09:49:31.659] 3 1 extra", want: false},
+		{line: "[10:30:00.000] 15 1", want: false},
+		{line: "[10:30:00.000] 16 1 one minute", want: false},
+		{line: "[10:30:00.000] 32 1", want: false},
+		{line: "09:05:59.867 1 1", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matches(tt.line) == 1, tt.line)
+	}
+
+	// Every line of the example events is an incoming event.
+	paths, err := filepath.Glob("examples/*/events")
+	assert.NoError(t, err)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		assert.NoError(t, err)
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				assert.Equal(t, 1, matches(line), "%s: %s", path, line)
+			}
+		}
+		f.Close()
+	}
+}
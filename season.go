@@ -0,0 +1,216 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Season describes a season of competitions whose results are added up into standings.
+type Season struct {
+	Points []int  `json:"points"` // Points awarded for the ranks from the first on, World Cup points by default
+	Roster string `json:"roster"` // Roster file with the names and nations of the competitors
+	Races  []Race `json:"races"`
+
+	competitors map[int]Competitor
+}
+
+// Race is a competition of the season read from the output of its run.
+type Race struct {
+	Name       string `json:"name"`
+	Discipline string `json:"discipline"`
+	Results    string `json:"results"`
+	Format     string `json:"format"` // The competition format, empty for an interval start
+
+	entries []ResultEntry
+}
+
+// SeasonStanding is the season result of a competitor.
+type SeasonStanding struct {
+	CompetitorID int
+	Competitor   Competitor
+	Points       int
+	Races        []string // The points or the status of the competitor in every race, "-" if not entered
+	Placings     []int    // The number of races finished on every rank from the first on
+}
+
+func (s SeasonStanding) String() string {
+	return fmt.Sprintf("[%d] %d%s {%s}", s.Points, s.CompetitorID, s.Competitor.label(), strings.Join(s.Races, ", "))
+}
+
+// loadSeason reads the season file and the results of its races.
+// The paths in the season file are relative to the directory of the season file.
+func loadSeason(path string) (Season, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Season{}, err
+	}
+	defer file.Close()
+
+	var season Season
+	if err := json.NewDecoder(file).Decode(&season); err != nil {
+		return Season{}, fmt.Errorf("parsing season: %w", err)
+	}
+	if len(season.Races) == 0 {
+		return Season{}, fmt.Errorf("season has no races")
+	}
+	if season.Points == nil {
+		season.Points = worldCupPoints
+	}
+
+	for i := range season.Races {
+		race := &season.Races[i]
+		if race.Name == "" {
+			race.Name = race.Results
+		}
+		if !slices.Contains([]string{FormatInterval, FormatPursuit, FormatMass, FormatRelay}, race.Format) {
+			return Season{}, fmt.Errorf("race %s has an unknown format %q", race.Name, race.Format)
+		}
+		race.entries, err = loadResults(relativeTo(path, race.Results))
+		if err != nil {
+			return Season{}, fmt.Errorf("loading results of %s: %w", race.Name, err)
+		}
+	}
+
+	if season.Roster != "" {
+		season.competitors, err = loadRoster(relativeTo(path, season.Roster))
+		if err != nil {
+			return Season{}, fmt.Errorf("loading roster: %w", err)
+		}
+	}
+
+	return season, nil
+}
+
+// loadResults reads the final report from the output of a previous run.
+func loadResults(path string) ([]ResultEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readResults(file)
+}
+
+// ranks ranks the finished competitors by their position in the final report.
+// In an interval start competitors with the same time share the rank, and the next rank is skipped.
+// Where the first competitor across the line wins, the finish decides and no rank is shared.
+func (race Race) ranks() map[int]int {
+	sharesRanks := !Config{Format: race.Format}.ranksByFinishTime()
+
+	ranks := make(map[int]int)
+	var finished []ResultEntry
+	for _, entry := range race.entries {
+		if !entry.Finished {
+			continue
+		}
+		rank := len(finished) + 1
+		if last := len(finished) - 1; sharesRanks && last >= 0 && finished[last].Time == entry.Time {
+			rank = ranks[finished[last].CompetitorID]
+		}
+		ranks[entry.CompetitorID] = rank
+		finished = append(finished, entry)
+	}
+	return ranks
+}
+
+// points returns the points awarded for the given rank.
+func (s Season) points(rank int) int {
+	if rank < 1 || rank > len(s.Points) {
+		return 0
+	}
+	return s.Points[rank-1]
+}
+
+// standings adds up the points of the races of the given discipline, or of all races if it is empty.
+// Competitors who did not finish a race get no points for it. A tie in points is broken
+// by the higher number of better placings.
+func (s Season) standings(discipline string) []SeasonStanding {
+	var races []Race
+	for _, race := range s.Races {
+		if discipline == "" || race.Discipline == discipline {
+			races = append(races, race)
+		}
+	}
+
+	byID := make(map[int]*SeasonStanding)
+	var standings []*SeasonStanding
+	for i, race := range races {
+		ranks := race.ranks()
+		for _, entry := range race.entries {
+			st, ok := byID[entry.CompetitorID]
+			if !ok {
+				st = &SeasonStanding{
+					CompetitorID: entry.CompetitorID,
+					Competitor:   s.competitors[entry.CompetitorID],
+					Races:        slices.Repeat([]string{"-"}, len(races)),
+					Placings:     make([]int, len(s.Points)),
+				}
+				byID[entry.CompetitorID] = st
+				standings = append(standings, st)
+			}
+
+			rank, finished := ranks[entry.CompetitorID]
+			if !finished {
+				st.Races[i] = entry.Status
+				continue
+			}
+			points := s.points(rank)
+			st.Points += points
+			st.Races[i] = fmt.Sprint(points)
+			if rank <= len(st.Placings) {
+				st.Placings[rank-1]++
+			}
+		}
+	}
+
+	result := make([]SeasonStanding, len(standings))
+	for i, st := range standings {
+		result[i] = *st
+	}
+	slices.SortStableFunc(result, func(a, b SeasonStanding) int {
+		return cmp.Or(
+			b.Points-a.Points,
+			-slices.Compare(a.Placings, b.Placings),
+			a.CompetitorID-b.CompetitorID,
+		)
+	})
+	return result
+}
+
+// disciplines returns the disciplines of the season in the order of their first race.
+func (s Season) disciplines() []string {
+	var disciplines []string
+	for _, race := range s.Races {
+		if race.Discipline != "" && !slices.Contains(disciplines, race.Discipline) {
+			disciplines = append(disciplines, race.Discipline)
+		}
+	}
+	return disciplines
+}
+
+// writeStandings writes ranked standings, competitors with the same points and placings share the rank.
+func writeStandings(w io.Writer, title string, standings []SeasonStanding) {
+	fmt.Fprintln(w, "[Season]", title)
+
+	rank := 0
+	for i, st := range standings {
+		if i == 0 || st.Points != standings[i-1].Points || !slices.Equal(st.Placings, standings[i-1].Placings) {
+			rank = i + 1
+		}
+		fmt.Fprintf(w, "%d. %s\n", rank, st)
+	}
+}
+
+// runSeason writes the overall standings of the season followed by the standings of every discipline.
+func runSeason(w io.Writer, season Season) {
+	writeStandings(w, "overall", season.standings(""))
+	for _, discipline := range season.disciplines() {
+		writeStandings(w, discipline, season.standings(discipline))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func finishedEntry(id int, d time.Duration) ResultEntry {
+	return ResultEntry{CompetitorID: id, Status: formatDuration(d), Time: d, Finished: true}
+}
+
+func TestRaceRanks(t *testing.T) {
+	entries := []ResultEntry{
+		{CompetitorID: 9, Status: "DNF"},
+		finishedEntry(1, 10*time.Minute),
+		finishedEntry(2, 11*time.Minute),
+		finishedEntry(3, 11*time.Minute),
+		finishedEntry(4, 12*time.Minute),
+	}
+
+	assert.Equal(t, map[int]int{1: 1, 2: 2, 3: 2, 4: 4}, Race{entries: entries}.ranks())
+
+	// The finish decides in a pursuit or a mass start, even with the same time.
+	for _, format := range []string{FormatPursuit, FormatMass, FormatRelay} {
+		assert.Equal(t, map[int]int{1: 1, 2: 2, 3: 3, 4: 4}, Race{Format: format, entries: entries}.ranks(), format)
+	}
+}
+
+func TestSeasonStandings(t *testing.T) {
+	season := Season{
+		Points: []int{10, 5, 3},
+		Races: []Race{
+			{Discipline: "sprint", entries: []ResultEntry{finishedEntry(1, time.Minute), finishedEntry(2, 2*time.Minute), finishedEntry(3, 3*time.Minute)}},
+			{Discipline: "pursuit", entries: []ResultEntry{finishedEntry(3, time.Minute), finishedEntry(2, 2*time.Minute), {CompetitorID: 1, Status: "DNF"}}},
+			{Discipline: "sprint", entries: []ResultEntry{finishedEntry(4, time.Minute)}},
+		},
+	}
+
+	overall := season.standings("")
+	require.Len(t, overall, 4)
+
+	assert.Equal(t, 3, overall[0].CompetitorID)
+	assert.Equal(t, 13, overall[0].Points)
+
+	// Competitors 1, 2 and 4 have 10 points each, 1 and 4 have won a race.
+	assert.Equal(t, 1, overall[1].CompetitorID)
+	assert.Equal(t, 10, overall[1].Points)
+	assert.Equal(t, []string{"10", "DNF", "-"}, overall[1].Races)
+	assert.Equal(t, 4, overall[2].CompetitorID)
+	assert.Equal(t, []string{"-", "-", "10"}, overall[2].Races)
+	assert.Equal(t, 2, overall[3].CompetitorID)
+	assert.Equal(t, []string{"5", "5", "-"}, overall[3].Races)
+
+	sprint := season.standings("sprint")
+	require.Len(t, sprint, 4)
+	assert.Equal(t, []string{"10", "-"}, sprint[0].Races)
+
+	assert.Equal(t, []string{"sprint", "pursuit"}, season.disciplines())
+}
+
+func TestWriteStandingsSharedRank(t *testing.T) {
+	standings := []SeasonStanding{
+		{CompetitorID: 1, Points: 10, Races: []string{"10"}, Placings: []int{1, 0}},
+		{CompetitorID: 2, Points: 10, Races: []string{"10"}, Placings: []int{1, 0}},
+		{CompetitorID: 3, Points: 5, Races: []string{"5"}, Placings: []int{0, 1}},
+	}
+
+	var buf bytes.Buffer
+	writeStandings(&buf, "overall", standings)
+
+	want := "[Season] overall\n" +
+		"1. [10] 1 {10}\n" +
+		"1. [10] 2 {10}\n" +
+		"3. [5] 3 {5}\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestLoadSeasonErrors(t *testing.T) {
+	_, err := loadSeason("non_existent_file")
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "season.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	_, err = loadSeason(write(`{"races": []}`))
+	assert.ErrorContains(t, err, "no races")
+
+	_, err = loadSeason(write(`{"races": [{"name": "Sprint", "results": "missing"}]}`))
+	assert.ErrorContains(t, err, "loading results of Sprint")
+
+	_, err = loadSeason(write(`{"races": [{"name": "Sprint", "format": "sprint", "results": "missing"}]}`))
+	assert.ErrorContains(t, err, `race Sprint has an unknown format "sprint"`)
+}
+
+func TestRunSeason(t *testing.T) {
+	season, err := loadSeason("examples/season/season.json")
+	require.NoError(t, err)
+	assert.Equal(t, worldCupPoints, season.Points)
+
+	want, err := os.ReadFile("examples/season/output")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	runSeason(&out, season)
+
+	assert.Equal(t, string(want), out.String())
+}
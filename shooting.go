@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// PositionStat holds the shooting accuracy in a single position.
+type PositionStat struct {
+	Position string
+	Hits     int
+	Shots    int
+}
+
+func (s PositionStat) String() string {
+	return fmt.Sprintf("%s %d/%d", s.Position, s.Hits, s.Shots)
+}
+
+// positionOf returns the shooting position of the bout with the given zero-based index.
+func (cfg Config) positionOf(bout int) string {
+	if bout < len(cfg.Positions) {
+		return cfg.Positions[bout]
+	}
+	return ""
+}
+
+// shotsFired returns the number of shots fired in the given number of bouts.
+// If single shots are not reported, every bout counts as a full set of targets plus the spare rounds loaded.
+func shotsFired(shots, bouts, targets, spares int) int {
+	if shots > 0 {
+		return shots
+	}
+	return bouts*targets + spares
+}
+
+// positionStats sums up the hits and shots of the bouts by shooting position.
+// The positions are listed in the order they were first shot in, bouts without a position are skipped.
+func positionStats(bouts []Bout, targets int) []PositionStat {
+	var stats []PositionStat
+	for _, b := range bouts {
+		if b.Position == "" {
+			continue
+		}
+		i := 0
+		for i < len(stats) && stats[i].Position != b.Position {
+			i++
+		}
+		if i == len(stats) {
+			stats = append(stats, PositionStat{Position: b.Position})
+		}
+		stats[i].Hits += b.Hits
+		stats[i].Shots += shotsFired(b.Shots, 1, targets, b.Spares)
+	}
+	return stats
+}
+
+// formatPositionStats returns the accuracy by position as " {prone 5/5, standing 3/5}".
+// It returns an empty string if there are no positions.
+func formatPositionStats(stats []PositionStat) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(stats))
+	for _, s := range stats {
+		parts = append(parts, s.String())
+	}
+	return " {" + strings.Join(parts, ", ") + "}"
+}
+
+// writeAccuracy writes the aggregate shooting accuracy of all competitors by position.
+// Nothing is written if the positions are not configured.
+func writeAccuracy(w io.Writer, targets int, summary Summary) {
+	var bouts []Bout
+	for _, st := range summary {
+		bouts = append(bouts, st.Bouts...)
+	}
+	stats := positionStats(bouts, targets)
+	if len(stats) == 0 {
+		return
+	}
+
+	// Keep the order of the positions independent of the order of the competitors in the summary.
+	slices.SortFunc(stats, func(a, b PositionStat) int {
+		return strings.Compare(a.Position, b.Position)
+	})
+
+	parts := make([]string, 0, len(stats))
+	for _, s := range stats {
+		parts = append(parts, fmt.Sprintf("{%s, %.1f%%}", s, accuracy(s.Hits, s.Shots)))
+	}
+	fmt.Fprintf(w, "[Accuracy] %s\n", strings.Join(parts, ", "))
+}
+
+// accuracy returns the share of hits in percent.
+func accuracy(hits, shots int) float64 {
+	if shots == 0 {
+		return 0
+	}
+	return 100 * float64(hits) / float64(shots)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionOf(t *testing.T) {
+	cfg := Config{Positions: []string{PositionProne, PositionStanding}}
+	assert.Equal(t, PositionProne, cfg.positionOf(0))
+	assert.Equal(t, PositionStanding, cfg.positionOf(1))
+	assert.Equal(t, "", cfg.positionOf(2))
+	assert.Equal(t, "", Config{}.positionOf(0))
+}
+
+func TestPositionStats(t *testing.T) {
+	bouts := []Bout{
+		{Position: PositionProne, Hits: 5},
+		{Position: PositionStanding, Hits: 3, Spares: 1},
+		{Position: PositionProne, Hits: 4, Shots: 6},
+		{Hits: 5},
+	}
+
+	stats := positionStats(bouts, 5)
+	assert.Equal(t, []PositionStat{
+		{Position: PositionProne, Hits: 9, Shots: 11},
+		{Position: PositionStanding, Hits: 3, Shots: 6},
+	}, stats)
+	assert.Equal(t, " {prone 9/11, standing 3/6}", formatPositionStats(stats))
+	assert.Equal(t, "", formatPositionStats(nil))
+}
+
+func TestWriteAccuracy(t *testing.T) {
+	summary := Summary{
+		1: {Bouts: []Bout{{Position: PositionStanding, Hits: 2}, {Position: PositionProne, Hits: 5}}},
+		2: {Bouts: []Bout{{Position: PositionProne, Hits: 3}}},
+	}
+
+	var buf bytes.Buffer
+	writeAccuracy(&buf, 5, summary)
+	assert.Equal(t, "[Accuracy] {prone 8/10, 80.0%}, {standing 2/5, 40.0%}\n", buf.String())
+
+	buf.Reset()
+	writeAccuracy(&buf, 5, Summary{1: {Bouts: []Bout{{Hits: 5}}}})
+	assert.Empty(t, buf.String())
+}
+
+func TestBoutPositions(t *testing.T) {
+	cfg := Config{Positions: []string{PositionProne, PositionStanding}}
+	st := &CompetitorState{}
+
+	require.NoError(t, updateState(cfg, Event{ID: EventStartedFiringRange, Extra: []string{"1"}}, st))
+	require.NoError(t, updateState(cfg, Event{ID: EventStartedFiringRange, Extra: []string{"2"}}, st))
+
+	assert.Equal(t, PositionProne, st.Bouts[0].Position)
+	assert.Equal(t, PositionStanding, st.Bouts[1].Position)
+}
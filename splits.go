@@ -0,0 +1,107 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Split is the time of a competitor, or of a relay team, at a checkpoint.
+type Split struct {
+	Rank   int // Equal times share a rank
+	ID     int // The competitor ID, or the team ID in a relay
+	Name   string
+	Time   time.Duration // Time since the actual start, of the first leg in a relay
+	Behind time.Duration // Gap to the leader at the checkpoint
+}
+
+// String returns the split in the format "1. [time] id name +behind". The leader has no gap.
+func (s Split) String() string {
+	line := fmt.Sprintf("%d. [%s] %d", s.Rank, formatDuration(s.Time), s.ID)
+	if s.Name != "" {
+		line += " " + s.Name
+	}
+	if s.Rank > 1 {
+		line += " +" + formatDuration(s.Behind)
+	}
+	return line
+}
+
+// CheckpointSplits holds the splits of everyone who passed a checkpoint in the order of their rank.
+type CheckpointSplits struct {
+	Checkpoint string
+	Splits     []Split
+}
+
+// splits ranks the competitors, or the relay teams, at every checkpoint by the time since their start.
+// The checkpoints are in the order they are passed.
+func splits(cfg Config, summary Summary) []CheckpointSplits {
+	all := entrants(cfg, summary)
+	slices.SortFunc(all, func(a, b entrant) int { return cmp.Compare(a.id, b.id) })
+
+	// A checkpoint comes after the ones passed before it by any competitor.
+	var names []string
+	position := make(map[string]int)
+	for _, e := range all {
+		for i, c := range e.checkpoints {
+			if _, ok := position[c.Name]; !ok {
+				names = append(names, c.Name)
+				position[c.Name] = i
+			}
+			position[c.Name] = min(position[c.Name], i)
+		}
+	}
+	slices.SortStableFunc(names, func(a, b string) int { return cmp.Compare(position[a], position[b]) })
+
+	result := make([]CheckpointSplits, 0, len(names))
+	for _, name := range names {
+		var checkpoint []Split
+		for _, e := range all {
+			i := slices.IndexFunc(e.checkpoints, func(c Checkpoint) bool { return c.Name == name })
+			if i < 0 {
+				continue
+			}
+			checkpoint = append(checkpoint, Split{ID: e.id, Name: e.name, Time: e.checkpoints[i].Time.Sub(e.start)})
+		}
+		slices.SortStableFunc(checkpoint, func(a, b Split) int { return cmp.Compare(a.Time, b.Time) })
+
+		for i := range checkpoint {
+			s := &checkpoint[i]
+			s.Rank = i + 1
+			if i > 0 && s.Time == checkpoint[i-1].Time {
+				s.Rank = checkpoint[i-1].Rank
+			}
+			s.Behind = s.Time - checkpoint[0].Time
+		}
+		result = append(result, CheckpointSplits{Checkpoint: name, Splits: checkpoint})
+	}
+	return result
+}
+
+// writeSplits writes a "[Splits] checkpoint" header followed by the splits for every checkpoint.
+func writeSplits(w io.Writer, checkpoints []CheckpointSplits) {
+	for _, c := range checkpoints {
+		fmt.Fprintln(w, "[Splits]", c.Checkpoint)
+		for _, s := range c.Splits {
+			fmt.Fprintln(w, s)
+		}
+	}
+}
+
+// runSplits processes the events and writes the splits report instead of the final report.
+// The events are not logged.
+func runSplits(eventsReader io.Reader, w io.Writer, cfg Config) {
+	if len(cfg.Competitions) > 0 {
+		for i, summary := range processCompetitions(eventsReader, io.Discard, cfg, nil) {
+			c := cfg.Competitions[i]
+			fmt.Fprintln(w, "[Competition]", c.ID)
+			writeSplits(w, splits(c, summary))
+		}
+		return
+	}
+
+	summary := processEvents(io.Discard, cfg, parseEvents(eventsReader, io.Discard))
+	writeSplits(w, splits(cfg, summary))
+}
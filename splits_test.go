@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitString(t *testing.T) {
+	assert.Equal(t, "1. [00:08:53.914] 1 Anna Berg (NOR)",
+		Split{Rank: 1, ID: 1, Name: "Anna Berg (NOR)", Time: 8*time.Minute + 53914*time.Millisecond}.String())
+	assert.Equal(t, "2. [00:09:00.454] 3 +00:00:06.540",
+		Split{Rank: 2, ID: 3, Time: 9*time.Minute + 454*time.Millisecond, Behind: 6540 * time.Millisecond}.String())
+}
+
+func TestSplits(t *testing.T) {
+	cfg := Config{Laps: 2, LapLen: LapLengths{1000}, FiringLines: 1, PenaltyLen: 150}
+	at := func(s string) time.Time { return parseTime(t, time.TimeOnly, s) }
+	summary := Summary{
+		1: {CompetitorID: 1, Status: StatusFinished, ActualStartTime: at("10:00:00"), Checkpoints: []Checkpoint{
+			{Name: "Shooting 1", Time: at("10:05:00")}, {Name: "Lap 1", Time: at("10:08:00")}, {Name: "Lap 2", Time: at("10:15:00")},
+		}},
+		2: {CompetitorID: 2, Status: StatusCantContinue, ActualStartTime: at("10:01:00"), Checkpoints: []Checkpoint{
+			{Name: "Shooting 1", Time: at("10:05:50")},
+		}},
+		3: {CompetitorID: 3, Status: StatusFinished, ActualStartTime: at("10:02:00"), Checkpoints: []Checkpoint{
+			{Name: "Shooting 1", Time: at("10:07:00")}, {Name: "Lap 1", Time: at("10:09:30")}, {Name: "Lap 2", Time: at("10:16:00")},
+		}},
+		4: {CompetitorID: 4, Status: StatusNotStarted},
+	}
+
+	got := splits(cfg, summary)
+	assert.Len(t, got, 3)
+	assert.Equal(t, []string{"Shooting 1", "Lap 1", "Lap 2"},
+		[]string{got[0].Checkpoint, got[1].Checkpoint, got[2].Checkpoint})
+
+	// Competitor 2 is the fastest to the range, competitors 1 and 3 share the second place.
+	assert.Equal(t, []Split{
+		{Rank: 1, ID: 2, Time: 4*time.Minute + 50*time.Second},
+		{Rank: 2, ID: 1, Time: 5 * time.Minute, Behind: 10 * time.Second},
+		{Rank: 2, ID: 3, Time: 5 * time.Minute, Behind: 10 * time.Second},
+	}, got[0].Splits)
+
+	// Competitor 3 is faster on the lap than competitor 1.
+	assert.Equal(t, []Split{
+		{Rank: 1, ID: 3, Time: 7*time.Minute + 30*time.Second},
+		{Rank: 2, ID: 1, Time: 8 * time.Minute, Behind: 30 * time.Second},
+	}, got[1].Splits)
+}
+
+func TestSplitsRelay(t *testing.T) {
+	cfg, err := loadConfig("examples/relay/config.json")
+	assert.NoError(t, err)
+	events, err := os.Open("examples/relay/events")
+	assert.NoError(t, err)
+	defer events.Close()
+
+	var out bytes.Buffer
+	runSplits(events, &out, cfg)
+
+	// The teams are timed from the start of the first leg.
+	assert.Contains(t, out.String(), "[Splits] Leg 2 Lap 2\n1. [00:39:29.800] 1 NOR\n2. [00:41:09.600] 2 GER +00:01:39.800\n")
+}
+
+func TestSplitsCompetitions(t *testing.T) {
+	cfg, err := loadConfig("examples/competitions/config.json")
+	assert.NoError(t, err)
+	events, err := os.Open("examples/competitions/events")
+	assert.NoError(t, err)
+	defer events.Close()
+
+	var out bytes.Buffer
+	runSplits(events, &out, cfg)
+
+	assert.Contains(t, out.String(), "[Competition] women\n[Splits] Shooting 1\n1. [00:08:53.914] 1 Anna Berg (NOR)\n")
+	assert.Contains(t, out.String(), "[Competition] men\n[Splits] Shooting 1\n1. [00:08:57.622] 2 Lars Holm (SWE)\n")
+}
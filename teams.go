@@ -0,0 +1,184 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Team classification settings.
+const (
+	TeamsByNation = "nation"
+	TeamsByClub   = "club"
+
+	ScoringTime   = "time"   // The team with the lowest sum of times wins
+	ScoringPoints = "points" // The team with the highest sum of World Cup points wins
+
+	TieBreakBest = "best" // A tie is broken by the better rank of the best member
+	TieBreakLast = "last" // A tie is broken by the better rank of the last counting member
+)
+
+// worldCupPoints holds the points awarded for the ranks from the first to the 40th.
+var worldCupPoints = []int{
+	90, 75, 60, 50, 45, 40, 36, 34, 32, 31,
+	30, 29, 28, 27, 26, 25, 24, 23, 22, 21,
+	20, 19, 18, 17, 16, 15, 14, 13, 12, 11,
+	10, 9, 8, 7, 6, 5, 4, 3, 2, 1,
+}
+
+// pointsFor returns the World Cup points for the given rank.
+func pointsFor(rank int) int {
+	if rank < 1 || rank > len(worldCupPoints) {
+		return 0
+	}
+	return worldCupPoints[rank-1]
+}
+
+// TeamClassification configures the classification of teams made of the best finishers of a nation or a club.
+type TeamClassification struct {
+	By       string `json:"by"`       // Whether teams are made by nation or by club
+	Best     int    `json:"best"`     // Number of the best finishers of a team counted
+	Scoring  string `json:"scoring"`  // Whether times or points are summed, time by default
+	TieBreak string `json:"tieBreak"` // Which member breaks a tie, the best one by default
+}
+
+func (tc TeamClassification) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(tc.By == TeamsByNation || tc.By == TeamsByClub,
+		"'teamClassification.by' must be %q or %q, got %q", TeamsByNation, TeamsByClub, tc.By)
+	check(tc.Best >= 1, "'teamClassification.best' must be positive, got %d", tc.Best)
+	check(tc.Scoring == "" || tc.Scoring == ScoringTime || tc.Scoring == ScoringPoints,
+		"'teamClassification.scoring' must be %q or %q, got %q", ScoringTime, ScoringPoints, tc.Scoring)
+	check(tc.TieBreak == "" || tc.TieBreak == TieBreakBest || tc.TieBreak == TieBreakLast,
+		"'teamClassification.tieBreak' must be %q or %q, got %q", TieBreakBest, TieBreakLast, tc.TieBreak)
+
+	return errors.Join(errs...)
+}
+
+// teamName returns the nation or the club the competitor is classified for.
+func (tc TeamClassification) teamName(c Competitor) string {
+	if tc.By == TeamsByClub {
+		return c.Club
+	}
+	return c.Nation
+}
+
+// TeamStanding is the result of a nation or a club made of its best finishers.
+type TeamStanding struct {
+	Name    string
+	Members []Result // The counting members in the order of their ranks
+	Ranks   []int    // The individual ranks of the counting members
+	Time    time.Duration
+	Points  int
+}
+
+// complete reports whether enough members of the team have finished to be classified.
+func (s TeamStanding) complete(tc TeamClassification) bool {
+	return len(s.Members) == tc.Best
+}
+
+// classifyTeams makes the team standings from the individual results in the order of the final report.
+// The classified teams come first, followed by the teams without enough finishers in the order of their best member.
+func classifyTeams(tc TeamClassification, results []Result) []TeamStanding {
+	var standings []TeamStanding
+	index := make(map[string]int)
+
+	rank := 0
+	for _, r := range results {
+		if r.Status != StatusFinished {
+			continue
+		}
+		rank++
+
+		name := tc.teamName(r.Competitor)
+		if name == "" {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(standings)
+			index[name] = i
+			standings = append(standings, TeamStanding{Name: name})
+		}
+
+		s := &standings[i]
+		if s.complete(tc) {
+			continue
+		}
+		s.Members = append(s.Members, r)
+		s.Ranks = append(s.Ranks, rank)
+		s.Time += r.TotalRaceDuration
+		s.Points += pointsFor(rank)
+	}
+
+	slices.SortStableFunc(standings, func(a, b TeamStanding) int {
+		switch aComplete, bComplete := a.complete(tc), b.complete(tc); {
+		case aComplete && !bComplete:
+			return -1
+		case !aComplete && bComplete:
+			return 1
+		case !aComplete && !bComplete:
+			return a.Ranks[0] - b.Ranks[0]
+		}
+
+		var score int
+		if tc.Scoring == ScoringPoints {
+			score = b.Points - a.Points
+		} else {
+			score = cmp.Compare(a.Time, b.Time)
+		}
+		tieBreak := a.Ranks[0] - b.Ranks[0]
+		if tc.TieBreak == TieBreakLast {
+			tieBreak = a.Ranks[len(a.Ranks)-1] - b.Ranks[len(b.Ranks)-1]
+		}
+		return cmp.Or(score, tieBreak, strings.Compare(a.Name, b.Name))
+	})
+
+	return standings
+}
+
+// writeTeamClassification writes the team classification of the final report. If the roster has categories,
+// the teams are classified separately in every category by the ranks of their members within it.
+func writeTeamClassification(w io.Writer, cfg Config, results []Result) {
+	tc := *cfg.TeamClassification
+	if !cfg.hasCategories() {
+		writeTeamStandings(w, tc, "", classifyTeams(tc, results))
+		return
+	}
+
+	categories, byCategory := groupByCategory(results)
+	for _, category := range categories {
+		writeTeamStandings(w, tc, category, classifyTeams(tc, byCategory[category]))
+	}
+}
+
+// writeTeamStandings writes a team classification section, the header names the category if there is one.
+func writeTeamStandings(w io.Writer, tc TeamClassification, category string, standings []TeamStanding) {
+	fmt.Fprintln(w, strings.TrimSpace("[Teams] "+tc.By+" "+category))
+
+	for i, s := range standings {
+		members := make([]string, len(s.Members))
+		for j, m := range s.Members {
+			members[j] = fmt.Sprintf("%d%s", m.CompetitorID, m.Competitor.label())
+		}
+
+		switch {
+		case !s.complete(tc):
+			fmt.Fprintf(w, "[INC] %s [%s]\n", s.Name, strings.Join(members, ", "))
+		case tc.Scoring == ScoringPoints:
+			fmt.Fprintf(w, "%d. [%d] %s [%s]\n", i+1, s.Points, s.Name, strings.Join(members, ", "))
+		default:
+			fmt.Fprintf(w, "%d. [%s] %s [%s]\n", i+1, formatDuration(s.Time), s.Name, strings.Join(members, ", "))
+		}
+	}
+}
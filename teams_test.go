@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointsFor(t *testing.T) {
+	assert.Equal(t, 90, pointsFor(1))
+	assert.Equal(t, 31, pointsFor(10))
+	assert.Equal(t, 30, pointsFor(11))
+	assert.Equal(t, 1, pointsFor(40))
+	assert.Equal(t, 0, pointsFor(41))
+	assert.Equal(t, 0, pointsFor(0))
+}
+
+func TestTeamClassificationValidate(t *testing.T) {
+	assert.NoError(t, TeamClassification{By: TeamsByNation, Best: 3}.validate())
+	assert.NoError(t, TeamClassification{By: TeamsByClub, Best: 1, Scoring: ScoringPoints, TieBreak: TieBreakLast}.validate())
+	assert.ErrorContains(t, TeamClassification{Best: 3}.validate(), "'teamClassification.by'")
+	assert.ErrorContains(t, TeamClassification{By: TeamsByNation}.validate(), "'teamClassification.best'")
+	assert.ErrorContains(t, TeamClassification{By: TeamsByNation, Best: 3, Scoring: "rank"}.validate(), "'teamClassification.scoring'")
+	assert.ErrorContains(t, TeamClassification{By: TeamsByNation, Best: 3, TieBreak: "first"}.validate(), "'teamClassification.tieBreak'")
+
+	// Every problem is reported.
+	err := TeamClassification{By: "school", Scoring: "rank", TieBreak: "first"}.validate()
+	assert.EqualError(t, err, `'teamClassification.by' must be "nation" or "club", got "school"
+'teamClassification.best' must be positive, got 0
+'teamClassification.scoring' must be "time" or "points", got "rank"
+'teamClassification.tieBreak' must be "best" or "last", got "first"`)
+}
+
+func finishedResult(id int, nation string, d time.Duration) Result {
+	return Result{
+		CompetitorState: &CompetitorState{CompetitorID: id, Status: StatusFinished, TotalRaceDuration: d},
+		Competitor:      Competitor{ID: id, Nation: nation},
+	}
+}
+
+func TestClassifyTeams(t *testing.T) {
+	// Ranks: 1 NOR, 2 SWE, 3 SWE, 4 NOR, 5 FRA, 6 NOR, and an unfinished GER.
+	results := []Result{
+		{CompetitorState: &CompetitorState{CompetitorID: 9, Status: StatusCantContinue}, Competitor: Competitor{Nation: "GER"}},
+		finishedResult(1, "NOR", 20*time.Minute),
+		finishedResult(2, "SWE", 20*time.Minute+30*time.Second),
+		finishedResult(3, "SWE", 21*time.Minute),
+		finishedResult(4, "NOR", 23*time.Minute),
+		finishedResult(5, "FRA", 24*time.Minute),
+		finishedResult(6, "NOR", 25*time.Minute),
+		finishedResult(7, "", 26*time.Minute),
+	}
+
+	t.Run("time", func(t *testing.T) {
+		standings := classifyTeams(TeamClassification{By: TeamsByNation, Best: 2}, results)
+		require.Len(t, standings, 3)
+		assert.Equal(t, "SWE", standings[0].Name)
+		assert.Equal(t, 41*time.Minute+30*time.Second, standings[0].Time)
+		assert.Equal(t, "NOR", standings[1].Name)
+		assert.Equal(t, 43*time.Minute, standings[1].Time)
+		assert.Equal(t, []int{1, 4}, standings[1].Ranks)
+		assert.Equal(t, "FRA", standings[2].Name)
+		assert.False(t, standings[2].complete(TeamClassification{By: TeamsByNation, Best: 2}))
+	})
+
+	t.Run("points", func(t *testing.T) {
+		standings := classifyTeams(TeamClassification{By: TeamsByNation, Best: 2, Scoring: ScoringPoints}, results)
+		require.Len(t, standings, 3)
+		assert.Equal(t, "NOR", standings[0].Name)
+		assert.Equal(t, 140, standings[0].Points)
+		assert.Equal(t, "SWE", standings[1].Name)
+		assert.Equal(t, 135, standings[1].Points)
+	})
+
+	t.Run("tie break", func(t *testing.T) {
+		tied := []Result{
+			finishedResult(1, "NOR", 10*time.Minute),
+			finishedResult(2, "SWE", 11*time.Minute),
+			finishedResult(3, "SWE", 12*time.Minute),
+			finishedResult(4, "NOR", 13*time.Minute),
+		}
+
+		standings := classifyTeams(TeamClassification{By: TeamsByNation, Best: 2}, tied)
+		assert.Equal(t, "NOR", standings[0].Name, "the best member of NOR is ranked first")
+
+		standings = classifyTeams(TeamClassification{By: TeamsByNation, Best: 2, TieBreak: TieBreakLast}, tied)
+		assert.Equal(t, "SWE", standings[0].Name, "the last counting member of SWE is ranked better")
+	})
+}
+
+func TestWriteTeamStandings(t *testing.T) {
+	tc := TeamClassification{By: TeamsByNation, Best: 2}
+	results := []Result{
+		finishedResult(1, "NOR", 10*time.Minute),
+		finishedResult(2, "SWE", 11*time.Minute),
+		finishedResult(3, "NOR", 12*time.Minute),
+	}
+
+	var buf bytes.Buffer
+	writeTeamStandings(&buf, tc, "", classifyTeams(tc, results))
+
+	want := "[Teams] nation\n" +
+		"1. [00:22:00.000] NOR [1 (NOR), 3 (NOR)]\n" +
+		"[INC] SWE [2 (SWE)]\n"
+	assert.Equal(t, want, buf.String())
+
+	entries, err := readResults(&buf)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "team lines must not be read back as individual results")
+}
+
+func TestWriteTeamClassificationCategories(t *testing.T) {
+	results := []Result{
+		finishedResult(1, "NOR", 20*time.Minute),
+		finishedResult(2, "SWE", 21*time.Minute),
+		finishedResult(3, "SWE", 22*time.Minute),
+		finishedResult(4, "NOR", 23*time.Minute),
+	}
+	results[0].Competitor.Gender, results[1].Competitor.Gender = "M", "M"
+	results[2].Competitor.Gender, results[3].Competitor.Gender = "F", "F"
+	cfg := Config{
+		TeamClassification: &TeamClassification{By: TeamsByNation, Best: 1, Scoring: ScoringPoints},
+		competitors:        map[int]Competitor{1: results[0].Competitor},
+	}
+
+	// The teams are classified by the ranks within every category, the women are not ranked behind the men.
+	var buf bytes.Buffer
+	writeTeamClassification(&buf, cfg, results)
+	want := "[Teams] nation F\n" +
+		"1. [90] SWE [3 (SWE)]\n" +
+		"2. [75] NOR [4 (NOR)]\n" +
+		"[Teams] nation M\n" +
+		"1. [90] NOR [1 (NOR)]\n" +
+		"2. [75] SWE [2 (SWE)]\n"
+	assert.Equal(t, want, buf.String())
+}